@@ -1,12 +1,8 @@
 package pcmd
 
 import (
-	"bufio"
-	"fmt"
+	"io"
 	"os"
-	"strings"
-
-	"github.com/pkg/errors"
 
 	"github.com/s-z-z/box/cprt"
 )
@@ -32,27 +28,25 @@ func NewPhaseRawfn(run func() error) PhaseInterface {
 }
 
 func NewPhaseConfirm() PhaseInterface {
+	return newPhaseConfirm(InteractivelyConfirmAction)
+}
+
+func newPhaseConfirm(confirm func(question string) error) PhaseInterface {
 	return Phase{
 		Name:   "_confirm",
 		Hidden: true,
 		Run: func() error {
-			return InteractivelyConfirmAction("Are you sure you want to proceed?")
+			return confirm("Are you sure you want to proceed?")
 		},
 	}
 }
 
 func InteractivelyConfirmAction(question string) error {
-	fmt.Printf("%s [y/N]: ", question)
-	r := os.Stdin
-	scanner := bufio.NewScanner(r)
-	scanner.Scan()
-	if err := scanner.Err(); err != nil {
-		return errors.Wrap(err, "couldn't read from standard input")
-	}
-	answer := scanner.Text()
-	if strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes") {
-		return nil
-	}
+	return ConfirmAction(os.Stdin, os.Stdout, question)
+}
 
-	return ErrUserAbort
+// ConfirmAction 从r读取一行确认, 回答y/yes之外返回ErrUserAbort
+// 多次读取同一输入时使用PhasesCmd共用的输入, 避免缓冲读取多余的行
+func ConfirmAction(r io.Reader, w io.Writer, question string) error {
+	return newInteractive(r, w).confirmAction(question)
 }
@@ -0,0 +1,33 @@
+package pcmd
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// GVKCompletionFunc 补全scheme中注册的GroupVersionKind, 格式为apiVersion/Kind, 如"example.io/v1/ClusterConfiguration"
+// 忽略internal版本及metav1注册的类型(ListOptions等)
+// 用于自定义的kind flag, 如: cmd.RegisterFlagCompletionFunc("kind", pcmd.GVKCompletionFunc(scheme))
+func GVKCompletionFunc(s *runtime.Scheme) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		metaPkg := reflect.TypeOf(metav1.TypeMeta{}).PkgPath()
+
+		var completions []cobra.Completion
+		for gvk, t := range s.AllKnownTypes() {
+			if gvk.Version == runtime.APIVersionInternal || t.PkgPath() == metaPkg {
+				continue
+			}
+			c := gvk.GroupVersion().String() + "/" + gvk.Kind
+			if strings.HasPrefix(c, toComplete) {
+				completions = append(completions, c)
+			}
+		}
+		sort.Strings(completions)
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,203 @@
+package pcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	boxutil "github.com/s-z-z/box/util"
+
+	"github.com/s-z-z/phasext/util"
+)
+
+// configCommand 返回config子命令, 不存在时创建
+// config子命令不解析和校验配置文件, 由各子命令自行处理
+func (p *PhasesCmd) configCommand() *cobra.Command {
+	for _, c := range p.cmd.Commands() {
+		if c.Name() == "config" {
+			return c
+		}
+	}
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the configuration file",
+		Args:  cobra.NoArgs,
+		// 覆盖根命令的PersistentPreRunE
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+	p.cmd.AddCommand(configCmd)
+	return configCmd
+}
+
+// addConfigMigrateCommand 添加config migrate子命令: 将配置文件中的旧版本转换为绑定数据对象的版本并回写
+func (p *PhasesCmd) addConfigMigrateCommand() {
+	p.configCommand().AddCommand(&cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate the configuration file to the latest API version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := p.requireConfigFlag(); err != nil {
+				return err
+			}
+			configPath := boxutil.GetAbsolutePath(p.configPath)
+			documentParser, err := File2DocumentParser(configPath, p.scheme)
+			if err != nil {
+				return errors.Wrapf(err, "pcmd:config:migrate: %s", configPath)
+			}
+			for gvk := range p.gvkObjects() {
+				if _, _, err := documentParser.Lookup(gvk); err != nil {
+					return errors.Wrapf(err, "pcmd:config:migrate: %s", configPath)
+				}
+			}
+
+			converted := documentParser.Converted()
+			if len(converted) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s is already at the latest API version\n", configPath)
+				return nil
+			}
+			if err := WriteBackObjects(configPath, documentParser.Dp, p.codec(), nil); err != nil {
+				return errors.Wrapf(err, "pcmd:config:migrate: %s", configPath)
+			}
+			var lines []string
+			for to, from := range converted {
+				lines = append(lines, fmt.Sprintf("migrated %s from %s to %s", to.Kind, from.GroupVersion(), to.GroupVersion()))
+			}
+			sort.Strings(lines)
+			fmt.Fprintln(cmd.OutOrStdout(), strings.Join(lines, "\n"))
+			return nil
+		},
+	})
+}
+
+const (
+	OutputYAML = "yaml"
+	OutputJSON = "json"
+)
+
+// addConfigViewCommands 添加config print-defaults和config view子命令
+func (p *PhasesCmd) addConfigViewCommands(flagKind util.FlagKind) {
+	var output string
+	var showSensitive bool
+	addOutputFlags := func(c *cobra.Command) {
+		c.Flags().StringVarP(&output, "output", "o", OutputYAML, fmt.Sprintf("Output format, one of: %s, %s", OutputYAML, OutputJSON))
+		c.Flags().BoolVar(&showSensitive, "show-sensitive", false, "Show the values of the sensitive fields instead of redacting them")
+		_ = c.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{OutputYAML, OutputJSON}, cobra.ShellCompDirectiveNoFileComp))
+	}
+
+	printDefaultsCmd := &cobra.Command{
+		Use:   "print-defaults",
+		Short: "Print the default configuration",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var objects []*dataObject
+			for _, o := range p.dataObjects() {
+				obj, err := p.scheme.New(o.gvk)
+				if err != nil {
+					return errors.Wrapf(err, "pcmd:config:print-defaults: %s", o.gvk.Kind)
+				}
+				p.scheme.Default(obj)
+				data, ok := obj.(WareHouse)
+				if !ok {
+					return errors.Errorf("pcmd:config:print-defaults: invalid object type %T", obj)
+				}
+				objects = append(objects, &dataObject{data: data, gvk: o.gvk})
+			}
+			return p.writeObjects(cmd.OutOrStdout(), objects, output, !showSensitive)
+		},
+	}
+	addOutputFlags(printDefaultsCmd)
+
+	viewCmd := &cobra.Command{
+		Use:   "view",
+		Short: "Print the effective configuration, merging the configuration file, defaults and flags",
+		Args:  cobra.NoArgs,
+	}
+	addOutputFlags(viewCmd)
+
+	// 导出的flag: Persistent时继承自根命令, 否则添加到view
+	fs, dataFlags := p.cmd.PersistentFlags(), p.dataFlags
+	if p.exportOverrideFlags && flagKind == util.Local {
+		fs, dataFlags = viewCmd.Flags(), p.exportDataFlags(viewCmd, p.data, flagKind)
+		for _, o := range p.extraData {
+			p.exportDataFlags(viewCmd, o.data, flagKind)
+		}
+	}
+	viewCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if err := p.requireConfigFlag(); err != nil {
+			return err
+		}
+		if p.withConfig {
+			p.configPath = boxutil.GetAbsolutePath(p.configPath)
+		} else {
+			p.configPath = ""
+		}
+		if err := p.loadData(fs, dataFlags); err != nil {
+			return err
+		}
+		return p.writeObjects(cmd.OutOrStdout(), p.dataObjects(), output, !showSensitive)
+	}
+
+	p.configCommand().AddCommand(printDefaultsCmd, viewCmd)
+}
+
+// writeObjects 以format格式输出数据对象, YAML以"---"分隔, JSON多个对象时输出v1 List(同kubectl)
+//
+//	redact: 将sensitive:"true"标记的字段替换为util.RedactedValue
+func (p *PhasesCmd) writeObjects(w io.Writer, objects []*dataObject, format string, redact bool) error {
+	if format != OutputYAML && format != OutputJSON {
+		return errors.Errorf("unknown output format %q, must be one of: %s, %s", format, OutputYAML, OutputJSON)
+	}
+
+	var items []map[string]interface{}
+	for _, o := range objects {
+		b, err := ObjectToJson(p.codec(), o.data, o.gvk)
+		if err != nil {
+			return errors.Wrapf(err, "pcmd:writeObjects:ObjectToJson: %s", o.gvk.Kind)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return errors.Wrapf(err, "pcmd:writeObjects:Unmarshal: %s", o.gvk.Kind)
+		}
+		if redact {
+			util.Redact(o.data, m)
+		}
+		items = append(items, m)
+	}
+
+	if format == OutputJSON {
+		var v interface{}
+		switch len(items) {
+		case 0:
+			return nil
+		case 1:
+			v = items[0]
+		default:
+			v = map[string]interface{}{"apiVersion": "v1", "kind": "List", "items": items}
+		}
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "pcmd:writeObjects:Marshal")
+		}
+		_, err = w.Write(append(b, '\n'))
+		return err
+	}
+
+	var docs []string
+	for _, m := range items {
+		b, err := yaml.Marshal(m)
+		if err != nil {
+			return errors.Wrapf(err, "pcmd:writeObjects:Marshal: %v", m["kind"])
+		}
+		docs = append(docs, string(b))
+	}
+	_, err := io.WriteString(w, strings.Join(docs, "---\n"))
+	return err
+}
@@ -0,0 +1,56 @@
+package pcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/s-z-z/phasext/workflow"
+)
+
+func TestConfigFlagRequired(t *testing.T) {
+	var usecases = []struct {
+		name          string
+		args          []string
+		expectedError bool
+	}{
+		{
+			name:          "workflow requires the config file",
+			args:          []string{},
+			expectedError: true,
+		},
+		{
+			name: "print-defaults does not require the config file",
+			args: []string{"config", "print-defaults"},
+		},
+		{
+			name: "phase graph does not require the config file",
+			args: []string{"phase", "graph"},
+		},
+		{
+			name:          "phase subcommands require the config file",
+			args:          []string{"phase", "foo"},
+			expectedError: true,
+		},
+		{
+			name:          "view requires the config file",
+			args:          []string{"config", "view"},
+			expectedError: true,
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			p := NewPhaseCmdFactory(newTestScheme(), nil).Create("app", WithData(&testClusterConfig{}), WithSpecConfigPath(""), WithConfigCommands(), WithPhaseBind(), WithGraphCommand())
+			p.AppendPhases(workflow.Phase{Name: "foo", Run: func(data workflow.RunData) error { return nil }})
+			_, err := executeTestCmd(p, u.args...)
+			if u.expectedError {
+				if err == nil || !strings.Contains(err.Error(), `required flag(s) "config" not set`) {
+					t.Errorf("expected required flag error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package pcmd
+
+import (
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/s-z-z/phasext/util"
+)
+
+// dataObject WithDataObjects绑定的额外数据对象
+// 每个对象独立的viper: 从配置文件中对应GVK的段解析, 只被自身导出的flag覆盖
+type dataObject struct {
+	data  WareHouse
+	gvk   schema.GroupVersionKind
+	viper *viper.Viper
+	flags map[string]bool
+}
+
+// DataSet 绑定的全部数据对象(WithData在前, WithDataObjects按顺序在后)和命令行参数, 作为runner数据传递给phase
+type DataSet struct {
+	Args    []string
+	objects []WareHouse
+}
+
+// Objects 全部数据对象
+func (d *DataSet) Objects() []WareHouse {
+	return d.objects
+}
+
+// GetData 返回第一个类型为T的数据对象
+func GetData[T WareHouse](d *DataSet) (T, bool) {
+	for _, o := range d.objects {
+		if t, ok := o.(T); ok {
+			return t, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// TypedDataSet 全部数据对象作为runner数据, phase直接接收*DataSet
+func TypedDataSet(p *PhasesCmd) *TypedPhasesCmd[*DataSet] {
+	return Typed(p, func(cmd *cobra.Command, args []string) (*DataSet, error) {
+		return &DataSet{Args: args, objects: p.Objects()}, nil
+	})
+}
+
+// Objects 全部数据对象: WithData绑定的对象在前
+func (p *PhasesCmd) Objects() []WareHouse {
+	if p.data == nil {
+		return nil
+	}
+	objects := []WareHouse{p.data}
+	for _, o := range p.extraData {
+		objects = append(objects, o.data)
+	}
+	return objects
+}
+
+// dataObjects 全部数据对象及其GVK: WithData绑定的对象在前
+func (p *PhasesCmd) dataObjects() []*dataObject {
+	if p.data == nil {
+		return nil
+	}
+	return append([]*dataObject{{data: p.data, gvk: p.gvk}}, p.extraData...)
+}
+
+// initExtraData 检查额外数据对象的GVK, 不允许与其它对象重复
+func (p *PhasesCmd) initExtraData() {
+	known := map[string]bool{p.gvk.Kind: true}
+	for _, o := range p.extraData {
+		gvk, err := GetGVKByObject(p.scheme, o.data)
+		if err != nil {
+			klog.Fatalf("pcmd:New: %s", err)
+		}
+		if known[gvk.Kind] {
+			klog.Fatalf("pcmd:New: kind %q is bound twice", gvk.Kind)
+		}
+		known[gvk.Kind] = true
+		o.gvk = gvk
+	}
+}
+
+// exportExtraDataFlags 导出额外数据对象的export字段, 记录每个对象自身的flag
+func (p *PhasesCmd) exportExtraDataFlags(flagKind util.FlagKind) {
+	for _, o := range p.extraData {
+		o.flags = p.exportDataFlags(p.cmd, o.data, flagKind)
+	}
+}
+
+// exportDataFlags 导出o的export字段至cmd, 返回新增的flag名称
+func (p *PhasesCmd) exportDataFlags(cmd *cobra.Command, o WareHouse, flagKind util.FlagKind) map[string]bool {
+	fs := cmd.Flags()
+	if flagKind == util.Persistent {
+		fs = cmd.PersistentFlags()
+	}
+	known := map[string]bool{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		known[f.Name] = true
+	})
+	util.AddExportFlags(cmd, o, p.specExportIncludeFlags, flagKind, false)
+	names := map[string]bool{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if !known[f.Name] {
+			names[f.Name] = true
+		}
+	})
+	return names
+}
+
+// fillExtraData 从配置文件解析额外数据对象, 配置文件中没有对应的段时只使用flag
+func (p *PhasesCmd) fillExtraData() error {
+	for _, o := range p.extraData {
+		var reader io.Reader = strings.NewReader("")
+		if p.documentParser != nil {
+			b, ok, err := p.documentParser.Lookup(o.gvk)
+			if err != nil {
+				return errors.Wrapf(err, "pcmd:parse:File2Reader:Reader: %s %s", o.gvk.Kind, p.configPath)
+			}
+			if ok {
+				reader = strings.NewReader(string(b))
+			}
+		}
+		if err := ReaderFillData(o.viper, reader, o.data); err != nil {
+			return errors.Wrapf(err, "pcmd:parse:Reader2Data: %s %s", o.gvk.Kind, p.configPath)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package pcmd
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testClusterDocument = "apiVersion: test.phasext.io/v1\nkind: ClusterConfig\nname: foo\n"
+
+func TestDataObjects(t *testing.T) {
+	var usecases = []struct {
+		name            string
+		config          string
+		args            []string
+		expectedCluster testClusterConfig
+		expectedNode    testNodeConfig
+		expectedError   string
+	}{
+		{
+			name:            "each object is loaded from its own document, with defaults and Init",
+			config:          testClusterDocument + "---\napiVersion: test.phasext.io/v1\nkind: NodeConfig\nnodeName: n1\n",
+			expectedCluster: testClusterConfig{Name: "foo", Port: 6443},
+			expectedNode:    testNodeConfig{NodeName: "n1", Role: "worker", Hostname: "n1.local"},
+		},
+		{
+			name:            "flags override only the fields of their own object",
+			config:          testClusterDocument + "---\napiVersion: test.phasext.io/v1\nkind: NodeConfig\nnodeName: n1\nrole: control-plane\n",
+			args:            []string{"--nodeName", "n2", "--port", "7000"},
+			expectedCluster: testClusterConfig{Name: "foo", Port: 7000},
+			expectedNode:    testNodeConfig{NodeName: "n2", Role: "control-plane", Hostname: "n2.local"},
+		},
+		{
+			name:            "missing document falls back to the flags",
+			config:          testClusterDocument,
+			args:            []string{"--nodeName", "n3"},
+			expectedCluster: testClusterConfig{Name: "foo", Port: 6443},
+			expectedNode:    testNodeConfig{NodeName: "n3", Role: "worker", Hostname: "n3.local"},
+		},
+		{
+			name:          "every object is validated",
+			config:        testClusterDocument + "---\napiVersion: test.phasext.io/v1\nkind: NodeConfig\nnodeName: n1\nrole: master\n",
+			expectedError: `invalid role "master"`,
+		},
+		{
+			name:          "validator runs on every object",
+			config:        testClusterDocument,
+			expectedError: "NodeName",
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			cluster, node := &testClusterConfig{}, &testNodeConfig{}
+			p := NewPhaseCmdFactory(newTestScheme(), validator.New()).Create("app",
+				WithDataObjects(cluster, node), WithSpecConfigPath(writeTestConfig(t, u.config)), WithExportOverrideFlags())
+
+			var objects []WareHouse
+			TypedDataSet(p).AppendTypedPhases(TypedPhase[*DataSet]{Name: "foo", Run: func(data *DataSet) error {
+				objects = data.Objects()
+				return nil
+			}})
+
+			_, err := executeTestCmd(p, u.args...)
+			if u.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), u.expectedError) {
+					t.Errorf("expected error containing %q, got %v", u.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			cluster.TypeMeta, node.TypeMeta = metav1.TypeMeta{}, metav1.TypeMeta{}
+			if *cluster != u.expectedCluster {
+				t.Errorf("\ncluster:\n\t%+v\nexpected:\n\t%+v\n", *cluster, u.expectedCluster)
+			}
+			if *node != u.expectedNode {
+				t.Errorf("\nnode:\n\t%+v\nexpected:\n\t%+v\n", *node, u.expectedNode)
+			}
+			// TypedDataSet: phase接收全部数据对象
+			if expected := []WareHouse{cluster, node}; !reflect.DeepEqual(objects, expected) {
+				t.Errorf("expected the phases to receive all the objects, got %v", objects)
+			}
+			if got, ok := GetData[*testNodeConfig](&DataSet{objects: objects}); !ok || got != node {
+				t.Errorf("expected GetData to return the node object, got %v", got)
+			}
+		})
+	}
+}
+
+func TestDataObjectsWriteBack(t *testing.T) {
+	configPath := writeTestConfig(t, testClusterDocument)
+	p := NewPhaseCmdFactory(newTestScheme(), nil).Create("app",
+		WithDataObjects(&testClusterConfig{}, &testNodeConfig{}), WithSpecConfigPath(configPath), WithExportOverrideFlags(), WithConfigWriteBack())
+	TypedDataSet(p).AppendTypedPhases(TypedPhase[*DataSet]{Name: "foo", Run: func(data *DataSet) error { return nil }})
+
+	if _, err := executeTestCmd(p, "--nodeName", "n1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// 文件中没有的对象追加在最后
+	documents := strings.Split(string(b), "---\n")
+	if len(documents) != 2 || !strings.Contains(documents[0], "kind: ClusterConfig") || !strings.Contains(documents[1], "kind: NodeConfig") || !strings.Contains(documents[1], "nodeName: n1") {
+		t.Errorf("expected the NodeConfig document to be appended, got:\n%s", b)
+	}
+}
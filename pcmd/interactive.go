@@ -0,0 +1,184 @@
+package pcmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/s-z-z/phasext/workflow"
+)
+
+// setupInteractive 选择要执行的phase作为FilterPhases, 并在每个phase执行前确认
+// 确认按顺序进行, 因此不并发执行
+func (p *PhasesCmd) setupInteractive() error {
+	in := p.prompt()
+	plan, err := p.Runner.Plan(p.Runner.Options)
+	if err != nil {
+		return errors.Wrap(err, "pcmd:interactive")
+	}
+	names, err := in.pick(plan)
+	if err != nil {
+		return err
+	}
+	if names != nil {
+		p.Runner.Options.FilterPhases = names
+	}
+	p.Runner.Options.BeforePhase = in.confirm
+	p.Runner.Options.Parallel = false
+	return nil
+}
+
+// interactive --interactive模式: 先选择要执行的phase, 每个phase执行前确认continue/skip/abort
+// 基于行输入, 非TTY(管道, 测试)同样可用
+type interactive struct {
+	r *bufio.Reader
+	w io.Writer
+}
+
+func newInteractive(r io.Reader, w io.Writer) *interactive {
+	return &interactive{r: bufio.NewReader(r), w: w}
+}
+
+// prompt PhasesCmd共用的交互输入输出: 命令的输入(InOrStdin)和输出(OutOrStdout)
+func (p *PhasesCmd) prompt() *interactive {
+	if p.prompter == nil {
+		p.prompter = newInteractive(p.cmd.InOrStdin(), p.cmd.OutOrStdout())
+	}
+	return p.prompter
+}
+
+// readLine 读取一行输入, EOF且无输入时返回io.EOF
+func (i *interactive) readLine() (string, error) {
+	line, err := i.r.ReadString('\n')
+	switch {
+	case err == io.EOF && line != "":
+		// 最后一行没有换行符
+	case err == io.EOF:
+		return "", err
+	case err != nil:
+		return "", errors.Wrap(err, "couldn't read from standard input")
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// pick 列出可执行的phase(不含隐藏和RunAllSiblings的phase), 返回选中phase的完整名称
+// 输入序号或范围, 逗号分隔, 如"1,3-5"; 直接回车选择全部, 返回nil
+// 选中phase时同时选中其子phase
+func (i *interactive) pick(plan *workflow.ExecutionPlan) ([]string, error) {
+	var entries []workflow.PlanEntry
+	for _, entry := range plan.Entries {
+		if entry.Run && !entry.Hidden && !entry.RunAllSiblings {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	fmt.Fprintln(i.w, "Select the phases to be executed:")
+	for n, entry := range entries {
+		fmt.Fprintf(i.w, "%3d) %s%s\n", n+1, strings.Repeat("  ", entry.Level), entry.Name)
+	}
+	for {
+		fmt.Fprint(i.w, "Phases (e.g. 1,3-5, empty for all): ")
+		line, err := i.readLine()
+		if err == io.EOF {
+			return nil, ErrUserAbort
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			return nil, nil
+		}
+
+		indexes, err := parseSelection(line, len(entries))
+		if err != nil {
+			fmt.Fprintf(i.w, "%s\n", err)
+			continue
+		}
+		names := make([]string, 0, len(indexes))
+		for _, n := range indexes {
+			names = append(names, entries[n].Name)
+		}
+		return names, nil
+	}
+}
+
+// parseSelection 解析"1,3-5"形式的选择, 返回从0开始的有序序号
+func parseSelection(s string, count int) ([]int, error) {
+	selected := map[int]bool{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(item, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, errors.Errorf("invalid selection %q", item)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+				return nil, errors.Errorf("invalid selection %q", item)
+			}
+		}
+		if start < 1 || end > count || start > end {
+			return nil, errors.Errorf("invalid selection %q, must be between 1 and %d", item, count)
+		}
+		for n := start; n <= end; n++ {
+			selected[n-1] = true
+		}
+	}
+	if len(selected) == 0 {
+		return nil, errors.Errorf("invalid selection %q", s)
+	}
+
+	indexes := make([]int, 0, len(selected))
+	for n := 0; n < count; n++ {
+		if selected[n] {
+			indexes = append(indexes, n)
+		}
+	}
+	return indexes, nil
+}
+
+// confirmAction 确认操作, 回答y/yes之外返回ErrUserAbort
+func (i *interactive) confirmAction(question string) error {
+	fmt.Fprintf(i.w, "%s [y/N]: ", question)
+	answer, err := i.readLine()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes") {
+		return nil
+	}
+	return ErrUserAbort
+}
+
+// confirm phase执行前确认: continue(默认)/skip/abort, 用作RunnerOptions.BeforePhase
+func (i *interactive) confirm(phase string) error {
+	for {
+		fmt.Fprintf(i.w, "Run phase %s? [c]ontinue/[s]kip/[a]bort: ", phase)
+		line, err := i.readLine()
+		if err == io.EOF {
+			return ErrUserAbort
+		}
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(line) {
+		case "", "c", "continue":
+			return nil
+		case "s", "skip":
+			return workflow.ErrSkipPhase
+		case "a", "abort":
+			return ErrUserAbort
+		}
+	}
+}
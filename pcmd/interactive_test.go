@@ -0,0 +1,165 @@
+package pcmd
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/s-z-z/phasext/workflow"
+)
+
+func TestParseSelection(t *testing.T) {
+	var usecases = []struct {
+		selection     string
+		expected      []int
+		expectedError bool
+	}{
+		{selection: "1", expected: []int{0}},
+		{selection: "1,3-5", expected: []int{0, 2, 3, 4}},
+		{selection: " 4 , 2 ", expected: []int{1, 3}},
+		{selection: "1,1-2", expected: []int{0, 1}},
+		{selection: "0", expectedError: true},
+		{selection: "6", expectedError: true},
+		{selection: "5-3", expectedError: true},
+		{selection: "1-x", expectedError: true},
+		{selection: "a", expectedError: true},
+		{selection: ",", expectedError: true},
+	}
+	for _, u := range usecases {
+		t.Run(u.selection, func(t *testing.T) {
+			actual, err := parseSelection(u.selection, 5)
+			if u.expectedError {
+				if err == nil {
+					t.Errorf("expected error, got %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, u.expected) {
+				t.Errorf("expected %v, got %v", u.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPick(t *testing.T) {
+	plan := &workflow.ExecutionPlan{Entries: []workflow.PlanEntry{
+		{Name: "foo", Run: true},
+		{Name: "foo/bar", Level: 1, Run: true},
+		{Name: "hidden", Run: true, Hidden: true},
+		{Name: "skipped", Run: false},
+		{Name: "baz", Run: true},
+	}}
+
+	var usecases = []struct {
+		name          string
+		input         string
+		expected      []string
+		expectedError error
+	}{
+		{
+			name:     "empty selects all",
+			input:    "\n",
+			expected: nil,
+		},
+		{
+			name:     "ranges select the listed phases",
+			input:    "2-3\n",
+			expected: []string{"foo/bar", "baz"},
+		},
+		{
+			name:     "invalid input prompts again",
+			input:    "x\n4\n1\n",
+			expected: []string{"foo"},
+		},
+		{
+			name:          "EOF aborts",
+			input:         "x\n",
+			expectedError: ErrUserAbort,
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			var out strings.Builder
+			actual, err := newInteractive(strings.NewReader(u.input), &out).pick(plan)
+			if !errors.Is(err, u.expectedError) {
+				t.Fatalf("expected error %v, got %v", u.expectedError, err)
+			}
+			if !reflect.DeepEqual(actual, u.expected) {
+				t.Errorf("expected %v, got %v", u.expected, actual)
+			}
+			// 只列出可执行且非隐藏的phase
+			if strings.Contains(out.String(), "hidden") || strings.Contains(out.String(), "skipped") {
+				t.Errorf("expected only the phases to be executed to be listed, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	var usecases = []struct {
+		input    string
+		expected error
+	}{
+		{input: "\n", expected: nil},
+		{input: "c\n", expected: nil},
+		{input: "continue", expected: nil},
+		{input: "S\n", expected: workflow.ErrSkipPhase},
+		{input: "abort\n", expected: ErrUserAbort},
+		{input: "x\ns\n", expected: workflow.ErrSkipPhase},
+		{input: "", expected: ErrUserAbort},
+	}
+	for _, u := range usecases {
+		t.Run(u.input, func(t *testing.T) {
+			err := newInteractive(strings.NewReader(u.input), io.Discard).confirm("foo")
+			if !errors.Is(err, u.expected) {
+				t.Errorf("expected %v, got %v", u.expected, err)
+			}
+		})
+	}
+}
+
+func TestConfirmAction(t *testing.T) {
+	var usecases = []struct {
+		input    string
+		expected error
+	}{
+		{input: "y\n", expected: nil},
+		{input: "YES\n", expected: nil},
+		{input: "\n", expected: ErrUserAbort},
+		{input: "no\n", expected: ErrUserAbort},
+		{input: "", expected: ErrUserAbort},
+	}
+	for _, u := range usecases {
+		t.Run(u.input, func(t *testing.T) {
+			err := newInteractive(strings.NewReader(u.input), io.Discard).confirmAction("proceed?")
+			if !errors.Is(err, u.expected) {
+				t.Errorf("expected %v, got %v", u.expected, err)
+			}
+		})
+	}
+}
+
+func TestInteractive(t *testing.T) {
+	p := NewPhaseCmdFactory(newTestScheme(), nil).Create("app", WithInteractive())
+	var ran []string
+	for _, name := range []string{"foo", "bar", "baz"} {
+		p.AppendPhases(workflow.Phase{Name: name, Run: func(data workflow.RunData) error {
+			ran = append(ran, name)
+			return nil
+		}})
+	}
+
+	// 选择foo和baz, 跳过foo, 执行baz
+	if _, err := executeTestCmdWithInput(p, strings.NewReader("1,3\ns\nc\n"), "--interactive"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"baz"}; !reflect.DeepEqual(ran, expected) {
+		t.Errorf("expected phases %v to run, got %v", expected, ran)
+	}
+}
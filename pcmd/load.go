@@ -0,0 +1,101 @@
+package pcmd
+
+import (
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/s-z-z/phasext/util"
+)
+
+// loadData 加载数据对象, 顺序:
+//  1. 配置文件: 没有配置文件时为空
+//  2. 默认值: scheme注册的defaulter(scheme.Default)
+//  3. flag覆盖: 只有命令行中设置的flag覆盖
+//
+// 之后依次执行preRunE2, Init(HasInit), 校验(validator, HasValidate)
+//
+//	fs: 导出flag所在的FlagSet
+//	dataFlags: WithData绑定的对象导出的flag
+func (p *PhasesCmd) loadData(fs *pflag.FlagSet, dataFlags map[string]bool) error {
+	// 支持空解析, 通过flag override
+	var reader io.Reader = strings.NewReader("")
+	if p.configPath != "" {
+		documentParser, err := File2DocumentParser(p.configPath, p.scheme)
+		if err != nil {
+			return errors.Wrapf(err, "pcmd:parse:File2Reader:NewDocumentParser: %s", p.configPath)
+		}
+		p.documentParser = documentParser
+		reader, err = p.GetReader()
+		if err != nil {
+			return errors.Wrapf(err, "pcmd:parse:File2Reader:Reader: %s", p.configPath)
+		}
+	}
+
+	if err := ReaderFillData(p.viper, reader, p.data); err != nil {
+		return errors.Wrapf(err, "pcmd:parse:Reader2Data: %s", p.configPath)
+	}
+
+	if err := p.fillExtraData(); err != nil {
+		return err
+	}
+
+	for _, o := range p.Objects() {
+		p.scheme.Default(o)
+	}
+
+	if !p.exportOverrideFlags {
+		return nil
+	}
+	if err := p.overrideByFlags(fs, dataFlags, p.data); err != nil {
+		return errors.Wrap(err, "pcmd:parse:FlagOverride")
+	}
+	for _, o := range p.extraData {
+		if err := p.overrideByFlags(fs, o.flags, o.data); err != nil {
+			return errors.Wrapf(err, "pcmd:parse:FlagOverride: %s", o.gvk.Kind)
+		}
+	}
+	return nil
+}
+
+// overrideByFlags 命令行中设置的o导出的flag(names)覆盖o的对应字段
+// viper经过viperFn定制, 与解析配置文件一致; 只解析flag对应的key, viperFn设置的默认值和环境变量不覆盖其它字段
+func (p *PhasesCmd) overrideByFlags(fs *pflag.FlagSet, names map[string]bool, o any) error {
+	fields, err := util.GetExportFields(o)
+	if err != nil {
+		return err
+	}
+	v := viper.New()
+	if p.viperFn != nil {
+		p.viperFn(v)
+	}
+	for _, field := range fields {
+		f := fs.Lookup(field.FlagName)
+		if !names[field.FlagName] || f == nil || !f.Changed {
+			continue
+		}
+		if err := v.BindPFlag(field.FlagName, f); err != nil {
+			return err
+		}
+		if err := v.UnmarshalKey(field.FlagName, field.FieldAddr); err != nil {
+			return errors.Wrapf(err, "flag --%s", field.FlagName)
+		}
+	}
+	return nil
+}
+
+// WriteConfig 输出全部数据对象的YAML, 多个对象以"---"分隔; 没有设置--show-sensitive时sensitive字段被替换
+func (p *PhasesCmd) WriteConfig(w io.Writer) error {
+	return p.writeObjects(w, p.dataObjects(), OutputYAML, !p.showSensitive)
+}
+
+// exportedFlagSet 导出flag所在的FlagSet
+func (p *PhasesCmd) exportedFlagSet() *pflag.FlagSet {
+	if p.persistentExportedFlag {
+		return p.cmd.PersistentFlags()
+	}
+	return p.cmd.Flags()
+}
@@ -0,0 +1,79 @@
+package pcmd
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestLoadDataOrder(t *testing.T) {
+	var usecases = []struct {
+		name         string
+		config       string
+		args         []string
+		expectedPort int
+	}{
+		{
+			name:         "default when neither file nor flag set the field",
+			config:       testClusterDocument,
+			expectedPort: 6443,
+		},
+		{
+			name:         "file overrides the default",
+			config:       testClusterDocument + "port: 7000\n",
+			expectedPort: 7000,
+		},
+		{
+			name:         "flag overrides the file",
+			config:       testClusterDocument + "port: 7000\n",
+			args:         []string{"--port", "8000"},
+			expectedPort: 8000,
+		},
+		{
+			name:         "flags not set on the command line do not override the file",
+			config:       testClusterDocument + "port: 7000\n",
+			args:         []string{"--name", "foo"},
+			expectedPort: 7000,
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			cluster := &testClusterConfig{}
+			p := NewPhaseCmdFactory(newTestScheme(), validator.New()).Create("app",
+				WithData(cluster), WithSpecConfigPath(writeTestConfig(t, u.config)), WithExportOverrideFlags())
+			TypedData(p, cluster).AppendTypedPhases(TypedPhase[*testClusterConfig]{Name: "foo", Run: func(data *testClusterConfig) error { return nil }})
+			if _, err := executeTestCmd(p, u.args...); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cluster.Port != u.expectedPort {
+				t.Errorf("expected port %d, got %d", u.expectedPort, cluster.Port)
+			}
+		})
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	// Init在flag覆盖之后执行; --print-config不校验(role无效), 不执行phase
+	config := testClusterDocument + "port: 7000\ntoken: secret\n---\n" +
+		"apiVersion: test.phasext.io/v1\nkind: NodeConfig\nnodeName: n1\nrole: master\n"
+	p := NewPhaseCmdFactory(newTestScheme(), validator.New()).Create("app",
+		WithDataObjects(&testClusterConfig{}, &testNodeConfig{}), WithSpecConfigPath(writeTestConfig(t, config)), WithExportOverrideFlags(), WithPrintConfig())
+	ran := false
+	TypedDataSet(p).AppendTypedPhases(TypedPhase[*DataSet]{Name: "foo", Run: func(data *DataSet) error {
+		ran = true
+		return nil
+	}})
+
+	out, err := executeTestCmd(p, "--print-config", "--port", "8000", "--nodeName", "n2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "apiVersion: test.phasext.io/v1\nkind: ClusterConfig\nname: foo\nport: 8000\ntoken: REDACTED\n---\n" +
+		"apiVersion: test.phasext.io/v1\nhostname: n2.local\nkind: NodeConfig\nnodeName: n2\nrole: master\n"
+	if out != expected {
+		t.Errorf("\nactual:\n%s\nexpected:\n%s\n", out, expected)
+	}
+	if ran {
+		t.Error("expected the phases not to run with --print-config")
+	}
+}
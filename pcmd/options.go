@@ -8,6 +8,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/s-z-z/phasext/util"
+	"github.com/s-z-z/phasext/workflow"
 )
 
 // WithData 绑定数据
@@ -20,15 +21,34 @@ func WithData(data WareHouse) Option {
 	}
 }
 
+// WithDataObjects 绑定多个数据对象, 如ClusterConfiguration和NodeConfiguration, 从同一个多段配置文件解析
+// 每个对象独立解析, Init, 校验, 导出flag(flag名不能重复)和回写; 配置文件中没有对应的段时只使用flag
+// 未设置WithData时第一个对象作为WithData绑定的对象; phase通过TypedDataSet接收全部对象
+func WithDataObjects(objs ...WareHouse) Option {
+	return func(p *PhasesCmd) {
+		for _, o := range objs {
+			if p.data == nil {
+				p.data = o
+				continue
+			}
+			p.extraData = append(p.extraData, &dataObject{data: o, viper: viper.New()})
+		}
+	}
+}
+
 func WithRunE(runE func(cmd *cobra.Command, args []string) error) Option {
 	return func(p *PhasesCmd) {
 		p.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			defer p.stopSignals()
+			if p.printConfig {
+				return nil
+			}
 			if runE != nil {
 				if err := runE(cmd, args); err != nil {
 					return err
 				}
 			}
-			return p.Runner.Run(args)
+			return p.runWorkflow(cmd, args)
 		}
 	}
 }
@@ -70,6 +90,42 @@ func WithConfigSpecFlag(cFlag string) Option {
 	}
 }
 
+// WithPrintConfig 添加--print-config参数: 打印加载(文件, 默认值, flag, Init)后校验前的配置, 不执行phase
+// sensitive字段默认被替换, --show-sensitive显示原值
+func WithPrintConfig() Option {
+	return func(p *PhasesCmd) {
+		p.withPrintConfig = true
+	}
+}
+
+// WithConfigCommands 添加config子命令:
+//   - config print-defaults: 打印scheme默认值(scheme.Default)填充的配置
+//   - config view: 打印合并配置文件, 默认值和flag后的配置, 不执行Init和校验
+//
+// 支持-o/--output输出yaml或json格式; sensitive:"true"标记的字段默认替换为REDACTED, --show-sensitive显示原值
+// json格式多个对象时输出为v1 List
+func WithConfigCommands() Option {
+	return func(p *PhasesCmd) {
+		p.withConfigCommands = true
+	}
+}
+
+// WithConfigMigrate 添加config migrate子命令: 将配置文件中的旧版本(apiVersion)通过scheme注册的转换函数转换为最新版本并回写
+// 不使用该子命令时, 旧版本在解析时同样被转换, 并打印废弃警告
+func WithConfigMigrate() Option {
+	return func(p *PhasesCmd) {
+		p.withConfigMigrate = true
+	}
+}
+
+// WithConfigValidate 添加config validate子命令: 对--config指定的配置文件执行分割, 严格解析, 默认值, validator和HasValidate校验
+// 一次报告全部问题(文件:行号: kind: 字段路径: 描述), 有问题时返回错误, 用于CI检查配置仓库
+func WithConfigValidate() Option {
+	return func(p *PhasesCmd) {
+		p.withConfigValidate = true
+	}
+}
+
 // WithExportOverrideFlags 导出export tag参数至命令行，flag > file
 // specIncludes不指定, 默认导出所有export=true字段
 // specIncludes指定, 仅导出specIncludes中的字段, 值为struct字段名
@@ -93,6 +149,73 @@ func WithPhaseBind() Option {
 	}
 }
 
+// WithParallel 并发执行相互独立的phase, 依赖关系由嵌套和Dependencies决定
+//
+//	maxParallelism: 最大并发数, 0表示不限制
+func WithParallel(maxParallelism int) Option {
+	return func(p *PhasesCmd) {
+		p.Runner.Options.Parallel = true
+		p.Runner.Options.MaxParallelism = maxParallelism
+	}
+}
+
+// WithCheckpoint 记录已完成的phase至checkpointPath, 并添加--resume参数跳过已完成的phase
+// 配置变化后checkpoint失效; checkpointPath为空时使用DefaultCheckpointPath
+func WithCheckpoint(checkpointPath string) Option {
+	return func(p *PhasesCmd) {
+		if checkpointPath == "" {
+			checkpointPath = DefaultCheckpointPath
+		}
+		p.Runner.Options.CheckpointFile = checkpointPath
+	}
+}
+
+// WithDryRun 添加--dry-run参数: 只打印执行计划, 不执行phase, 不回写配置
+func WithDryRun() Option {
+	return func(p *PhasesCmd) {
+		p.withDryRun = true
+	}
+}
+
+// WithInteractive 添加--interactive参数: 从phase列表中选择要执行的phase, 每个phase执行前确认continue/skip/abort
+// 选择子集时隐藏的phase(如WithConfirm添加的phase)不执行; 基于行输入, 非TTY同样可用
+func WithInteractive() Option {
+	return func(p *PhasesCmd) {
+		p.withInteractive = true
+	}
+}
+
+// WithReport 添加--report参数: 执行后输出每个phase的执行报告, .json后缀为JSON格式, 其它为YAML格式
+//
+//	reportPath: 默认路径, 为空时不输出报告
+func WithReport(reportPath string) Option {
+	return func(p *PhasesCmd) {
+		p.withReport = true
+		p.reportPath = reportPath
+	}
+}
+
+// WithReportStore 执行报告包含workflow store的内容; store中的敏感数据将明文输出
+func WithReportStore() Option {
+	return func(p *PhasesCmd) {
+		p.Runner.Options.ReportStore = true
+	}
+}
+
+// WithSkipLogVerbosity 跳过phase(filter, skip-phases, RunIf等)的日志级别, 默认workflow.DefaultSkipLogVerbosity, 0输出为INFO
+func WithSkipLogVerbosity(level int) Option {
+	return func(p *PhasesCmd) {
+		p.Runner.Options.SkipLogVerbosity = klog.Level(level)
+	}
+}
+
+// WithObserver 监听phase的生命周期事件: started/skipped/succeeded/failed, 用于日志, 监控, 进度展示
+func WithObserver(o workflow.Observer) Option {
+	return func(p *PhasesCmd) {
+		p.Runner.AddObserver(o)
+	}
+}
+
 // WithPersistentExportedFlag 导出的命令绑定到 PersistentFlags，意味着所有的phase子命令将自动继承
 func WithPersistentExportedFlag() Option {
 	return func(p *PhasesCmd) {
@@ -170,3 +293,10 @@ func WithPostRun(p1, p2 CobraRun) Option {
 		p.postRunE2 = p2
 	}
 }
+
+// WithGraphCommand 添加隐藏的phase graph子命令: 以DOT或Mermaid格式(--format)打印phase树及依赖关系, 需要WithPhaseBind
+func WithGraphCommand() Option {
+	return func(p *PhasesCmd) {
+		p.Runner.EnableGraphCommand()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -18,6 +19,8 @@ import (
 type DocumentParser struct {
 	Dp     DocumentMap
 	scheme *runtime.Scheme
+	// converted 转换后的版本 -> 配置文件中的旧版本
+	converted map[schema.GroupVersionKind]schema.GroupVersionKind
 }
 
 type DocumentParser2Redaer func(dp *DocumentParser) (io.Reader, error)
@@ -44,8 +47,9 @@ func NewDocumentParser(fpath string, scheme *runtime.Scheme) (*DocumentParser, e
 		}
 	}
 	return &DocumentParser{
-		Dp:     gvk2b,
-		scheme: scheme,
+		Dp:        gvk2b,
+		scheme:    scheme,
+		converted: map[schema.GroupVersionKind]schema.GroupVersionKind{},
 	}, nil
 }
 
@@ -58,12 +62,42 @@ func (g *DocumentParser) GetBytesByObj(o schema.ObjectKind) ([]byte, bool) {
 	return g.GetBytesByGvk(o.GroupVersionKind())
 }
 
+// Lookup 返回gvk的段, 配置文件中是同group和kind的旧版本时, 转换为gvk版本并替换原来的段
+func (g *DocumentParser) Lookup(gvk schema.GroupVersionKind) ([]byte, bool, error) {
+	if b, ok := g.GetBytesByGvk(gvk); ok {
+		return b, true, nil
+	}
+	for from, b := range g.Dp {
+		if from.Group != gvk.Group || from.Kind != gvk.Kind {
+			continue
+		}
+		converted, err := ConvertDocument(g.scheme, b, from, gvk)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "Lookup:ConvertDocument: convert %s to %s error", from.GroupVersion(), gvk.GroupVersion())
+		}
+		klog.Warningf("apiVersion %s of kind %s is deprecated, please use %s", from.GroupVersion(), gvk.Kind, gvk.GroupVersion())
+		delete(g.Dp, from)
+		g.Dp[gvk] = converted
+		g.converted[gvk] = from
+		return converted, true, nil
+	}
+	return nil, false, nil
+}
+
+// Converted 转换过的段: 转换后的版本 -> 配置文件中的旧版本
+func (g *DocumentParser) Converted() map[schema.GroupVersionKind]schema.GroupVersionKind {
+	return g.converted
+}
+
 func (g *DocumentParser) GetBytes(o WareHouse) ([]byte, error) {
 	gvk, err := GetGVKByObject(g.scheme, o)
 	if err != nil {
 		return nil, errors.Wrap(err, "GetBytes:GetGVKByObject: get object kind error")
 	}
-	b, ok := g.GetBytesByGvk(gvk)
+	b, ok, err := g.Lookup(gvk)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetBytes:Lookup")
+	}
 	if !ok {
 		return nil, errors.Errorf("GetBytes:GetObjBytes: not found: %+v", gvk)
 	}
@@ -95,6 +129,32 @@ func (g *DocumentParser) Fill(o WareHouse, codecs serializer.CodecFactory) error
 	return runtime.DecodeInto(codecs.UniversalDecoder(), b, o)
 }
 
+// ConvertDocument 通过scheme注册的转换函数将from版本的段转换为to版本
+// 没有from到to的转换函数时, 经由internal版本转换
+func ConvertDocument(scheme *runtime.Scheme, b []byte, from, to schema.GroupVersionKind) ([]byte, error) {
+	codecs := serializer.NewCodecFactory(scheme)
+	obj, _, err := codecs.UniversalDeserializer().Decode(b, &from, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "ConvertDocument:Decode")
+	}
+
+	out, err := scheme.ConvertToVersion(obj, to.GroupVersion())
+	if err != nil {
+		internal, ierr := scheme.ConvertToVersion(obj, schema.GroupVersion{Group: to.Group, Version: runtime.APIVersionInternal})
+		if ierr != nil {
+			return nil, errors.Wrap(err, "ConvertDocument:ConvertToVersion")
+		}
+		if out, err = scheme.ConvertToVersion(internal, to.GroupVersion()); err != nil {
+			return nil, errors.Wrap(err, "ConvertDocument:ConvertToVersion")
+		}
+	}
+	o, ok := out.(WareHouse)
+	if !ok {
+		return nil, errors.Errorf("ConvertDocument: invalid object type %T", out)
+	}
+	return ObjectToYaml(codecs, o, to)
+}
+
 func OnlyUnmarshalSelf(o WareHouse) DocumentParser2Redaer {
 	return func(dp *DocumentParser) (io.Reader, error) {
 		return dp.SelfReader(o)
@@ -174,25 +234,63 @@ func RepalceDocument(parser DocumentMap, codec serializer.CodecFactory, o WareHo
 }
 
 func WriteBackFile(configPath string, parser DocumentMap, codec serializer.CodecFactory, o WareHouse, gvk schema.GroupVersionKind) error {
+	return WriteBackObjects(configPath, parser, codec, map[schema.GroupVersionKind]WareHouse{gvk: o})
+}
+
+// ReplaceDocuments 替换多个对象的段, 文件中没有的对象按GVK排序追加在最后
+func ReplaceDocuments(parser DocumentMap, codec serializer.CodecFactory, objects map[schema.GroupVersionKind]WareHouse) (string, error) {
+	var yamls []string
+	for gvk, b := range parser {
+		if o, ok := objects[gvk]; ok {
+			_b, err := ObjectToYaml(codec, o, gvk)
+			if err != nil {
+				return "", errors.Wrap(err, "pcmd:parse:ReplaceDocuments:ObjectToYaml")
+			}
+			b = _b
+		}
+		yamls = append(yamls, string(b))
+	}
+
+	var missing []schema.GroupVersionKind
+	for gvk := range objects {
+		if _, ok := parser[gvk]; !ok {
+			missing = append(missing, gvk)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].String() < missing[j].String()
+	})
+	for _, gvk := range missing {
+		b, err := ObjectToYaml(codec, objects[gvk], gvk)
+		if err != nil {
+			return "", errors.Wrap(err, "pcmd:parse:ReplaceDocuments:ObjectToYaml")
+		}
+		yamls = append(yamls, string(b))
+	}
+	return strings.Join(yamls, "---\n"), nil
+}
+
+// WriteBackObjects 回写多个对象至配置文件
+func WriteBackObjects(configPath string, parser DocumentMap, codec serializer.CodecFactory, objects map[schema.GroupVersionKind]WareHouse) error {
 
-	data, err := RepalceDocument(parser, codec, o, gvk)
+	data, err := ReplaceDocuments(parser, codec, objects)
 	if err != nil {
-		return errors.Wrap(err, "pcmd:parse:WriteBackFile:RepalceDocument")
+		return errors.Wrap(err, "pcmd:parse:WriteBackObjects:ReplaceDocuments")
 	}
 
 	klog.V(7).Infof("write back to file: %s", configPath)
 
 	f, err := os.OpenFile(configPath, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0644)
 	if err != nil {
-		return errors.Wrap(err, "pcmd:parse:WriteBackFile:OpenFile")
+		return errors.Wrap(err, "pcmd:parse:WriteBackObjects:OpenFile")
 	}
 
 	if _, err = f.WriteString(data); err != nil {
-		return errors.Wrap(err, "pcmd:parse:WriteBackFile:WriteString")
+		return errors.Wrap(err, "pcmd:parse:WriteBackObjects:WriteString")
 	}
 
 	if err = f.Close(); err != nil {
-		return errors.Wrap(err, "pcmd:parse:WriteBackFile:CloseFile")
+		return errors.Wrap(err, "pcmd:parse:WriteBackObjects:CloseFile")
 	}
 	return nil
 }
@@ -0,0 +1,81 @@
+package pcmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"k8s.io/klog/v2"
+)
+
+const testOldClusterDocument = "apiVersion: test.phasext.io/v1alpha1\nkind: ClusterConfig\nclusterName: foo\nport: 7000\n"
+
+func TestConvertDocument(t *testing.T) {
+	// v1alpha1 -> v1没有转换函数, 经由internal版本转换
+	b, err := ConvertDocument(newTestScheme(), []byte(testOldClusterDocument), testOldGroupVersion.WithKind("ClusterConfig"), testGroupVersion.WithKind("ClusterConfig"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "apiVersion: test.phasext.io/v1\nkind: ClusterConfig\nname: foo\nport: 7000\n"
+	if string(b) != expected {
+		t.Errorf("\nactual:\n%s\nexpected:\n%s\n", b, expected)
+	}
+}
+
+func TestLoadOldVersion(t *testing.T) {
+	var logs bytes.Buffer
+	klog.LogToStderr(false)
+	klog.SetOutput(&logs)
+	defer func() {
+		klog.SetOutput(os.Stderr)
+		klog.LogToStderr(true)
+	}()
+
+	cluster := &testClusterConfig{}
+	p := NewPhaseCmdFactory(newTestScheme(), nil).Create("app", WithData(cluster), WithSpecConfigPath(writeTestConfig(t, testOldClusterDocument)))
+	TypedData(p, cluster).AppendTypedPhases(TypedPhase[*testClusterConfig]{Name: "foo", Run: func(data *testClusterConfig) error { return nil }})
+	if _, err := executeTestCmd(p); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	klog.Flush()
+
+	if cluster.Name != "foo" || cluster.Port != 7000 {
+		t.Errorf("expected the old version to be converted, got %+v", *cluster)
+	}
+	warning := "apiVersion test.phasext.io/v1alpha1 of kind ClusterConfig is deprecated, please use test.phasext.io/v1"
+	if !strings.Contains(logs.String(), warning) {
+		t.Errorf("expected deprecation warning %q, got:\n%s", warning, logs.String())
+	}
+}
+
+func TestConfigMigrate(t *testing.T) {
+	configPath := writeTestConfig(t, testOldClusterDocument)
+	newCmd := func() *PhasesCmd {
+		return NewPhaseCmdFactory(newTestScheme(), nil).Create("app", WithData(&testClusterConfig{}), WithSpecConfigPath(configPath), WithConfigMigrate())
+	}
+
+	out, err := executeTestCmd(newCmd(), "config", "migrate")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "migrated ClusterConfig from test.phasext.io/v1alpha1 to test.phasext.io/v1\n"; out != expected {
+		t.Errorf("expected output %q, got %q", expected, out)
+	}
+	b, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "apiVersion: test.phasext.io/v1\nkind: ClusterConfig\nname: foo\nport: 7000\n"; string(b) != expected {
+		t.Errorf("\nconfig:\n%s\nexpected:\n%s\n", b, expected)
+	}
+
+	// 已是最新版本时不回写
+	out, err = executeTestCmd(newCmd(), "config", "migrate")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "is already at the latest API version") {
+		t.Errorf("expected no migration, got %q", out)
+	}
+}
@@ -3,8 +3,15 @@ package pcmd
 // phase command
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/pkg/errors"
@@ -47,12 +54,21 @@ func NewPhaseCmdFactory(s *runtime.Scheme, v *validator.Validate) *PhaseCmdFacto
 }
 
 type PhasesCmd struct {
-	cmd                         *cobra.Command
-	Runner                      *workflow.Runner
-	data                        WareHouse
-	gvk                         schema.GroupVersionKind
-	firstAppend                 bool
-	withConfirm                 bool
+	cmd             *cobra.Command
+	Runner          *workflow.Runner
+	data            WareHouse
+	gvk             schema.GroupVersionKind
+	extraData       []*dataObject
+	dataFlags       map[string]bool
+	firstAppend     bool
+	withConfirm     bool
+	withDryRun      bool
+	withInteractive bool
+	interactive     bool
+	// prompter 交互输入输出, 确认phase和--interactive共用, 避免各自缓冲读取同一输入
+	prompter                    *interactive
+	withReport                  bool
+	reportPath                  string
 	withConfig                  bool
 	configFlag                  string
 	configPath                  string
@@ -68,10 +84,18 @@ type PhasesCmd struct {
 	bindToCommand               bool
 	finished                    bool
 	configWriteBack             bool
+	withConfigMigrate           bool
+	withConfigCommands          bool
+	withConfigValidate          bool
+	withPrintConfig             bool
+	printConfig                 bool
+	showSensitive               bool
 	v                           *validator.Validate
 	shouldValidate              bool
 	viper                       *viper.Viper
 	viperFn                     func(*viper.Viper)
+	// stopInterrupt 停止监听SIGINT/SIGTERM
+	stopInterrupt context.CancelFunc
 	// preRunE1 load data前执行
 	preRunE1 CobraRun
 	// preRunE2 load data后执行
@@ -104,12 +128,9 @@ func newPhaseCmdByProp(prop CmdProp) *PhasesCmd {
 		Hidden:                 prop.Hidden,
 		SilenceUsage:           prop.SilenceUsage,
 		SilenceErrors:          prop.SilenceErrors,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return runner.Run(args)
-		},
 	}
 
-	return &PhasesCmd{
+	p := &PhasesCmd{
 		cmd:             cmd,
 		Runner:          runner,
 		configPath:      DefaultConfigPath,
@@ -119,6 +140,25 @@ func newPhaseCmdByProp(prop CmdProp) *PhasesCmd {
 		firstAppend:     true,
 		viper:           viper.New(),
 	}
+	cmd.RunE = p.runWorkflow
+	return p
+}
+
+// runWorkflow 执行workflow, 执行后输出报告
+func (p *PhasesCmd) runWorkflow(cmd *cobra.Command, args []string) error {
+	defer p.stopSignals()
+	if p.printConfig {
+		return nil
+	}
+	if p.interactive {
+		if err := p.setupInteractive(); err != nil {
+			return err
+		}
+	}
+	// dry-run的执行计划输出到命令的输出
+	p.Runner.SetOutput(cmd.OutOrStdout())
+	err := p.Runner.RunContext(cmdContext(cmd), args)
+	return p.writeReport(err)
 }
 
 func newPhasesCmd(prop CmdProp, opts ...Option) *PhasesCmd {
@@ -139,12 +179,25 @@ func newPhasesCmd(prop CmdProp, opts ...Option) *PhasesCmd {
 			klog.Fatalf("pcmd:New: %s", err)
 		}
 		p.gvk = gvk
+		p.initExtraData()
 	}
 
 	if p.configWriteBack && !p.withConfig {
 		klog.Fatalf("pcmd:New: If configWriteBack, WithConfig must be set")
 	}
 
+	if p.withConfigMigrate && !p.withConfig {
+		klog.Fatalf("pcmd:New: If WithConfigMigrate, WithConfig must be set")
+	}
+
+	if p.withConfigValidate && !p.withConfig {
+		klog.Fatalf("pcmd:New: If WithConfigValidate, WithConfig must be set")
+	}
+
+	if p.withConfigCommands && p.data == nil {
+		klog.Fatalf("pcmd:New: If WithConfigCommands, WithData must be set")
+	}
+
 	// 控制顺序
 	p.init()
 
@@ -157,6 +210,42 @@ func (p *PhasesCmd) init() {
 		util.AddConfigFlag(p.cmd, p.configFlag, &p.configPath)
 	}
 
+	// 支持从checkpoint恢复
+	if p.Runner.Options.CheckpointFile != "" {
+		p.cmd.PersistentFlags().BoolVar(&p.Runner.Options.Resume, "resume", false, "Skip the phases completed by the previous execution")
+	}
+
+	// 支持dry-run
+	if p.withDryRun {
+		p.cmd.PersistentFlags().BoolVar(&p.Runner.Options.DryRun, "dry-run", false, "Print the phases that would be executed, without executing them")
+	}
+
+	// 支持交互式选择phase
+	if p.withInteractive {
+		p.cmd.Flags().BoolVar(&p.interactive, "interactive", false, "Select the phases to be executed and confirm each phase before executing it")
+	}
+
+	// 支持输出执行报告
+	if p.withReport {
+		p.cmd.PersistentFlags().StringVar(&p.reportPath, "report", p.reportPath, "Path of the execution report, in JSON (.json) or YAML format")
+	}
+
+	// 支持打印加载后的配置
+	if p.withPrintConfig {
+		p.cmd.Flags().BoolVar(&p.printConfig, "print-config", false, "Print the configuration after applying defaults, flags and Init, and exit")
+		p.cmd.Flags().BoolVar(&p.showSensitive, "show-sensitive", false, "Show the values of the sensitive fields printed by --print-config instead of redacting them")
+	}
+
+	// 支持config migrate
+	if p.withConfigMigrate {
+		p.addConfigMigrateCommand()
+	}
+
+	// 支持config validate
+	if p.withConfigValidate {
+		p.addConfigValidateCommand()
+	}
+
 	// 注入PersistentPreRunE: 检查scheme, 解析文件, Unmarshal
 	p.documentToDataPersistentPreRun()
 
@@ -173,6 +262,14 @@ func (p *PhasesCmd) init() {
 
 	if p.viperFn != nil {
 		p.viperFn(p.viper)
+		for _, o := range p.extraData {
+			p.viperFn(o.viper)
+		}
+	}
+
+	// 支持config print-defaults, config view: 需要导出flag之后添加
+	if p.withConfigCommands {
+		p.addConfigViewCommands(flagKind)
 	}
 }
 
@@ -182,11 +279,47 @@ func (p *PhasesCmd) finalize() {
 	// runner数据初始化: 返回Data
 	if p.runnerDataInitializer == nil {
 		p.Runner.SetDataInitializer(util.OnlyArgsDataInitializer)
+	} else {
+		p.Runner.SetDataInitializer(p.runnerDataInitializer)
+	}
+
+	// 依赖检查: 未知phase或循环依赖
+	if err := p.Runner.ValidateDependencies(); err != nil {
+		klog.Fatalf("pcmd:Cmd: %s", err)
+	}
+
+	// 支持按tag选择phase
+	if tags := p.Runner.Tags(); len(tags) > 0 {
+		p.cmd.Flags().StringSliceVar(&p.Runner.Options.FilterTags, "phase-tags", nil, fmt.Sprintf("List of tags of the phases to be executed, one of: %s", strings.Join(tags, ", ")))
+		p.cmd.Flags().StringSliceVar(&p.Runner.Options.SkipTags, "skip-phase-tags", nil, fmt.Sprintf("List of tags of the phases to be skipped, one of: %s", strings.Join(tags, ", ")))
 	}
 
 	// 支持Phase
 	if p.bindToCommand {
 		p.Runner.BindToCommand(p.cmd)
+		p.wrapPhaseCommands()
+	}
+}
+
+// wrapPhaseCommands phase子命令执行后同样输出报告, 并停止监听信号
+func (p *PhasesCmd) wrapPhaseCommands() {
+	var wrap func(c *cobra.Command)
+	wrap = func(c *cobra.Command) {
+		if runE := c.RunE; runE != nil {
+			c.RunE = func(cmd *cobra.Command, args []string) error {
+				defer p.stopSignals()
+				p.Runner.SetOutput(cmd.OutOrStdout())
+				return p.writeReport(runE(cmd, args))
+			}
+		}
+		for _, sub := range c.Commands() {
+			wrap(sub)
+		}
+	}
+	for _, c := range p.cmd.Commands() {
+		if c.Name() == "phase" {
+			wrap(c)
+		}
 	}
 }
 
@@ -196,13 +329,8 @@ func (p *PhasesCmd) _exportOverrideFlags(flagKind util.FlagKind) {
 		return
 	}
 
-	util.AddExportFlags(p.cmd, p.data, p.specExportIncludeFlags, flagKind, false)
-
-	if flagKind == util.Local {
-		_ = p.viper.BindPFlags(p.cmd.Flags())
-	} else {
-		_ = p.viper.BindPFlags(p.cmd.PersistentFlags())
-	}
+	p.dataFlags = p.exportDataFlags(p.cmd, p.data, flagKind)
+	p.exportExtraDataFlags(flagKind)
 }
 
 func (p *PhasesCmd) _exportExtraFlags(flagKind util.FlagKind) {
@@ -223,7 +351,11 @@ func (p *PhasesCmd) documentToDataPersistentPreRun() {
 
 	originPersistentPreRunE := p.cmd.PersistentPreRunE
 
-	p.cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+	preRunE := func(cmd *cobra.Command, args []string) error {
+		if err := p.requireConfigFlag(); err != nil {
+			return err
+		}
+
 		if p.preRunE1 != nil {
 			if err := p.preRunE1(cmd, args); err != nil {
 				return err
@@ -238,22 +370,9 @@ func (p *PhasesCmd) documentToDataPersistentPreRun() {
 		}
 
 		if p.data != nil {
-			// 支持空解析, viper绑定默认数据结构,通过flag override
-			var reader io.Reader = strings.NewReader("")
-			if p.configPath != "" {
-				documentParser, err := File2DocumentParser(p.configPath, p.scheme)
-				if err != nil {
-					return errors.Wrapf(err, "pcmd:parse:File2Reader:NewDocumentParser: %s", p.configPath)
-				}
-				p.documentParser = documentParser
-				reader, err = p.GetReader()
-				if err != nil {
-					return errors.Wrapf(err, "pcmd:parse:File2Reader:Reader: %s", p.configPath)
-				}
-			}
-
-			if err := ReaderFillData(p.viper, reader, p.data); err != nil {
-				return errors.Wrapf(err, "pcmd:parse:Reader2Data: %s", p.configPath)
+			// 文件 -> scheme默认值 -> flag覆盖
+			if err := p.loadData(p.exportedFlagSet(), p.dataFlags); err != nil {
+				return err
 			}
 
 			if p.preRunE2 != nil {
@@ -268,11 +387,25 @@ func (p *PhasesCmd) documentToDataPersistentPreRun() {
 			return err
 		}
 
+		// --print-config: 打印加载后的配置, 不校验, 不执行phase
+		if p.printConfig {
+			return p.WriteConfig(cmd.OutOrStdout())
+		}
+
 		// go validate
 		if err := p.toValidate(); err != nil {
 			return err
 		}
 
+		// checkpoint绑定当前配置
+		if p.Runner.Options.CheckpointFile != "" {
+			key, err := p.checkpointKey()
+			if err != nil {
+				return errors.Wrap(err, "pcmd:checkpointKey")
+			}
+			p.Runner.Options.CheckpointKey = key
+		}
+
 		if p.preRunE3 != nil {
 			if err := p.preRunE3(cmd, args); err != nil {
 				return err
@@ -285,13 +418,78 @@ func (p *PhasesCmd) documentToDataPersistentPreRun() {
 
 		return nil
 	}
+
+	p.cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// SIGINT/SIGTERM 取消workflow的ctx, 命令结束时停止监听
+		ctx, stop := withInterrupt(cmdContext(cmd))
+		cmd.SetContext(ctx)
+		p.stopInterrupt = stop
+		if err := preRunE(cmd, args); err != nil {
+			p.stopSignals()
+			return err
+		}
+		return nil
+	}
+
+	// 其它子命令执行成功后停止监听; 根命令和phase子命令在RunE中停止(RunE失败时cobra不执行PostRun)
+	if originPersistentPostRunE := p.cmd.PersistentPostRunE; originPersistentPostRunE != nil {
+		p.cmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+			defer p.stopSignals()
+			return originPersistentPostRunE(cmd, args)
+		}
+		return
+	}
+	originPersistentPostRun := p.cmd.PersistentPostRun
+	p.cmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		defer p.stopSignals()
+		if originPersistentPostRun != nil {
+			originPersistentPostRun(cmd, args)
+		}
+	}
+}
+
+// requireConfigFlag WithConfig且没有默认路径时, --config必须设置
+// 不使用cobra的required标记: config子命令(如print-defaults)和phase graph不需要配置文件
+func (p *PhasesCmd) requireConfigFlag() error {
+	if p.withConfig && p.configPath == "" {
+		return errors.Errorf("required flag(s) %q not set", p.configFlag)
+	}
+	return nil
+}
+
+// stopSignals 停止监听SIGINT/SIGTERM, 恢复默认的信号处理
+func (p *PhasesCmd) stopSignals() {
+	if p.stopInterrupt != nil {
+		p.stopInterrupt()
+		p.stopInterrupt = nil
+	}
+}
+
+// withInterrupt 返回收到SIGINT/SIGTERM时被取消的ctx, 以及停止监听的函数, 命令结束时必须调用
+// 第一次信号之后恢复默认的信号处理, 再次中断将直接退出
+func withInterrupt(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+func cmdContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
 }
 
 func (p *PhasesCmd) dataInit() error {
-	if p.data != nil {
-		v, ok := p.data.(HasInit)
+	for _, o := range p.Objects() {
+		v, ok := o.(HasInit)
 		if ok {
-			return v.Init()
+			if err := v.Init(); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -302,12 +500,12 @@ func (p *PhasesCmd) toValidate() error {
 		return nil
 	}
 
-	if p.data != nil {
-		if err := p.v.Struct(p.data); err != nil {
+	for _, o := range p.Objects() {
+		if err := p.v.Struct(o); err != nil {
 			return err
 		}
 
-		v, ok := p.data.(HasValidate)
+		v, ok := o.(HasValidate)
 		if ok {
 			if err := v.Validate(); err != nil {
 				return err
@@ -330,6 +528,27 @@ func (p *PhasesCmd) toValidate() error {
 	return nil
 }
 
+// checkpointKey 配置的hash, 配置变化后checkpoint失效
+func (p *PhasesCmd) checkpointKey() (string, error) {
+	if p.data == nil {
+		return "", nil
+	}
+	h := sha256.New()
+	b, err := p.GetDataYaml()
+	if err != nil {
+		return "", err
+	}
+	h.Write(b)
+	for _, o := range p.extraData {
+		b, err := ObjectToYaml(p.codec(), o.data, o.gvk)
+		if err != nil {
+			return "", err
+		}
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (p *PhasesCmd) GetValidator() *validator.Validate {
 	return p.v
 }
@@ -337,6 +556,10 @@ func (p *PhasesCmd) GetValidator() *validator.Validate {
 func (p *PhasesCmd) dataToDocumentPostRun() {
 	originPostRunE := p.cmd.PostRunE
 	p.cmd.PostRunE = func(cmd *cobra.Command, args []string) error {
+		if p.printConfig {
+			return nil
+		}
+
 		if originPostRunE != nil {
 			if err := originPostRunE(cmd, args); err != nil {
 				return err
@@ -349,8 +572,8 @@ func (p *PhasesCmd) dataToDocumentPostRun() {
 			}
 		}
 
-		if p.configWriteBack {
-			if err := WriteBackFile(p.configPath, p.documentParser.Dp, p.codec(), p.data, p.gvk); err != nil {
+		if p.configWriteBack && !p.Runner.Options.DryRun {
+			if err := WriteBackObjects(p.configPath, p.documentParser.Dp, p.codec(), p.gvkObjects()); err != nil {
 				return errors.Wrapf(err, "pcmd:parse:WriteBackFile: %s", p.configPath)
 			}
 			klog.V(5).Info("write back success")
@@ -366,6 +589,15 @@ func (p *PhasesCmd) dataToDocumentPostRun() {
 	}
 }
 
+// gvkObjects 全部数据对象及其GVK
+func (p *PhasesCmd) gvkObjects() map[schema.GroupVersionKind]WareHouse {
+	objects := map[schema.GroupVersionKind]WareHouse{}
+	for _, o := range p.dataObjects() {
+		objects[o.gvk] = o.data
+	}
+	return objects
+}
+
 func (p *PhasesCmd) GetConfigPath() string {
 	return p.configPath
 }
@@ -411,12 +643,18 @@ func (p *PhasesCmd) AppendPhases(phases ...workflow.Phase) {
 
 	if p.withConfirm && p.firstAppend {
 		p.firstAppend = false
-		p.Runner.AppendPhase(NewPhaseSpew(p.data).convert2workflowPhase())
+		var spew any = p.data
+		if len(p.extraData) > 0 {
+			spew = p.Objects()
+		}
+		p.Runner.AppendPhase(NewPhaseSpew(spew).convert2workflowPhase())
 		confirmBeforeRun, ok := p.data.(HasConfirmBeforeRun)
 		if ok {
 			p.Runner.AppendPhase(NewPhaseRawfn(confirmBeforeRun.ConfirmBeforeRun).convert2workflowPhase())
 		}
-		p.Runner.AppendPhase(NewPhaseConfirm().convert2workflowPhase())
+		p.Runner.AppendPhase(newPhaseConfirm(func(question string) error {
+			return p.prompt().confirmAction(question)
+		}).convert2workflowPhase())
 	}
 
 	for _, phase := range phases {
@@ -0,0 +1,96 @@
+package pcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/s-z-z/phasext/workflow"
+)
+
+func TestStopSignals(t *testing.T) {
+	fail := func(workflow.RunData) error { return errors.New("failed") }
+
+	var usecases = []struct {
+		name string
+		opts []Option
+		args []string
+	}{
+		{
+			name: "workflow fails",
+		},
+		{
+			name: "phase subcommand fails",
+			opts: []Option{WithPhaseBind()},
+			args: []string{"phase", "foo"},
+		},
+		{
+			name: "WithRunE hook fails",
+			opts: []Option{WithRunE(func(cmd *cobra.Command, args []string) error {
+				return errors.New("failed")
+			})},
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			p := NewPhaseCmdFactory(newTestScheme(), nil).Create("app", u.opts...)
+			p.AppendPhases(workflow.Phase{Name: "foo", Run: fail})
+			if _, err := executeTestCmd(p, u.args...); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if p.stopInterrupt != nil {
+				t.Error("expected the signal handler to be stopped")
+			}
+		})
+	}
+}
+
+func TestStopSignalsChainsPersistentPostRun(t *testing.T) {
+	called := false
+	p := NewPhaseCmdFactory(newTestScheme(), nil).Create("app", func(p *PhasesCmd) {
+		p.cmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+			called = true
+		}
+	})
+	p.cmd.AddCommand(&cobra.Command{Use: "foo", Run: func(cmd *cobra.Command, args []string) {}})
+	if _, err := executeTestCmd(p, "foo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !called {
+		t.Error("expected the PersistentPostRun of the command to be called")
+	}
+	if p.stopInterrupt != nil {
+		t.Error("expected the signal handler to be stopped")
+	}
+}
+
+func TestDryRunOutput(t *testing.T) {
+	var usecases = []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "workflow",
+			args: []string{"--dry-run"},
+		},
+		{
+			name: "phase subcommand",
+			args: []string{"phase", "foo", "--dry-run"},
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			p := NewPhaseCmdFactory(newTestScheme(), nil).Create("app", WithDryRun(), WithPhaseBind())
+			p.AppendPhases(workflow.Phase{Name: "foo", Run: func(workflow.RunData) error { return nil }})
+			out, err := executeTestCmd(p, u.args...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !strings.Contains(out, "foo") {
+				t.Errorf("expected the execution plan in the command output, got %q", out)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package pcmd
 
 import (
+	"context"
+	"time"
+
 	"github.com/pkg/errors"
 
 	"github.com/s-z-z/phasext/workflow"
@@ -41,22 +44,45 @@ type Phase struct {
 	// Nb. phase marked as RunAllSiblings can not have Run functions
 	RunAllSiblings bool
 
-	// Run: 优先级RunArgs>RunAny>Run
+	// Run: 优先级RunContext>RunArgs>RunAny>Run
 	Run func() error
 
-	// RunAny: 优先级RunArgs>RunAny>Run
+	// RunAny: 优先级RunContext>RunArgs>RunAny>Run
 	RunAny func(initializerData any) error
 
-	// RunArgs: 优先级RunArgs>RunAny>Run
+	// RunArgs: 优先级RunContext>RunArgs>RunAny>Run
 	RunArgs func(args []string) error
 
+	// RunContext: 优先级RunContext>RunArgs>RunAny>Run, 中断(SIGINT/SIGTERM)或超时后ctx被取消
+	// phase间传递结果: workflow.StoreFromContext(ctx)获取本次执行的Store
+	RunContext func(ctx context.Context, initializerData any) error
+
+	// RunIf 执行条件: 返回false时跳过phase, reason为跳过原因, 记录在日志和执行报告中
+	RunIf func(initializerData any) (ok bool, reason string, err error)
+
+	// Timeout phase执行超时时间, 0表示不超时; 重试时每次执行单独计时
+	Timeout time.Duration
+
+	// Retry 失败重试策略, nil表示不重试
+	Retry *workflow.RetryPolicy
+
+	// Rollback 撤销phase的操作: 后续phase失败时, 已执行phase的Rollback按逆序调用
+	Rollback func(initializerData any) error
+
+	// DryRun dry-run模式下代替Run执行, 打印phase将要执行的操作
+	DryRun func(initializerData any) error
+
 	// InheritFlags defines the list of flags that the cobra command generated for this phase should Inherit
 	// from local flags defined in the parent command / or additional flags defined in the phase runner.
 	// If the values is not set or empty, no flags will be assigned to the command
 	// Nb. global flags are automatically inherited by nested cobra command
 	InheritFlags []string
 
+	// Tags phase标签, 用于跨子树选择phase(--phase-tags, --skip-phase-tags), 选中phase时同时选中其子phase
+	Tags []string
+
 	// Dependencies is a list of phases that the specific phase depends on.
+	// 同级phase可直接写名称(如"ca"), 其他phase使用完整路径(如"certs/ca"), 以"/"开头强制按完整路径解析
 	Dependencies []string
 }
 
@@ -69,11 +95,14 @@ func (p Phase) convert2workflowPhase() workflow.Phase {
 		Example:        p.Example,
 		Hidden:         p.Hidden,
 		RunAllSiblings: p.RunAllSiblings,
-		Run: func(initializerData workflow.RunData) error {
+		RunContext: func(ctx context.Context, initializerData workflow.RunData) error {
+			if p.RunContext != nil {
+				return p.RunContext(ctx, initializerData)
+			}
 			if p.RunArgs != nil {
 				s, ok := initializerData.([]string)
 				if !ok {
-					return errors.New("convert2workflowPhase:invalid data type, expected []string")
+					return errors.Errorf("convert2workflowPhase:invalid data type %T, expected []string; use TypedPhase for custom runner data", initializerData)
 				}
 				return p.RunArgs(s)
 			}
@@ -82,7 +111,13 @@ func (p Phase) convert2workflowPhase() workflow.Phase {
 			}
 			return p.Run()
 		},
+		RunIfReason:  p.RunIf,
+		Timeout:      p.Timeout,
+		Retry:        p.Retry,
+		Rollback:     p.Rollback,
+		DryRun:       p.DryRun,
 		InheritFlags: p.InheritFlags,
+		Tags:         p.Tags,
 		Dependencies: p.Dependencies,
 	}
 }
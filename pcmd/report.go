@@ -0,0 +1,54 @@
+package pcmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
+
+	"github.com/s-z-z/phasext/workflow"
+)
+
+// writeReport 输出最近一次执行的报告, 返回workflow的执行错误
+func (p *PhasesCmd) writeReport(runErr error) error {
+	if p.reportPath == "" {
+		return runErr
+	}
+
+	report := p.Runner.Report()
+	if report == nil {
+		return runErr
+	}
+
+	if err := WriteReport(p.reportPath, report); err != nil {
+		if runErr == nil {
+			return err
+		}
+		klog.Errorf("pcmd:writeReport: %v", err)
+	}
+	return runErr
+}
+
+// WriteReport 写入执行报告: .json后缀为JSON格式, 其它为YAML格式
+func WriteReport(reportPath string, report *workflow.Report) error {
+	var b []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(reportPath), ".json") {
+		b, err = json.MarshalIndent(report, "", "  ")
+	} else {
+		b, err = yaml.Marshal(report)
+	}
+	if err != nil {
+		return errors.Wrap(err, "pcmd:report:WriteReport:Marshal")
+	}
+
+	if err := os.WriteFile(reportPath, b, 0644); err != nil {
+		return errors.Wrapf(err, "pcmd:report:WriteReport:WriteFile: %s", reportPath)
+	}
+	klog.V(1).Infof("write report to: %s", reportPath)
+	return nil
+}
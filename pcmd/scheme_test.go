@@ -0,0 +1,156 @@
+package pcmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// 测试用的scheme: test.phasext.io/v1 ClusterConfig, NodeConfig
+// 旧版本test.phasext.io/v1alpha1 ClusterConfig只能经由internal版本转换为v1
+
+var (
+	testGroupVersion         = schema.GroupVersion{Group: "test.phasext.io", Version: "v1"}
+	testOldGroupVersion      = schema.GroupVersion{Group: "test.phasext.io", Version: "v1alpha1"}
+	testInternalGroupVersion = schema.GroupVersion{Group: "test.phasext.io", Version: runtime.APIVersionInternal}
+)
+
+type testClusterConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Name  string `json:"name" export:"true" validate:"required"`
+	Port  int    `json:"port" export:"true" validate:"min=1,max=65535"`
+	Token string `json:"token,omitempty" sensitive:"true"`
+}
+
+func (c *testClusterConfig) DeepCopyObject() runtime.Object {
+	out := *c
+	return &out
+}
+
+// testClusterConfigV1alpha1 v1alpha1版本: name的旧名称为clusterName
+type testClusterConfigV1alpha1 struct {
+	metav1.TypeMeta `json:",inline"`
+
+	ClusterName string `json:"clusterName"`
+	Port        int    `json:"port"`
+}
+
+func (c *testClusterConfigV1alpha1) DeepCopyObject() runtime.Object {
+	out := *c
+	return &out
+}
+
+// testClusterConfigInternal internal版本
+type testClusterConfigInternal struct {
+	metav1.TypeMeta
+
+	Name  string
+	Port  int
+	Token string
+}
+
+func (c *testClusterConfigInternal) DeepCopyObject() runtime.Object {
+	out := *c
+	return &out
+}
+
+type testNodeConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	NodeName string `json:"nodeName" export:"true" validate:"required"`
+	Role     string `json:"role,omitempty"`
+	// Hostname Init设置
+	Hostname string `json:"hostname,omitempty"`
+}
+
+func (c *testNodeConfig) DeepCopyObject() runtime.Object {
+	out := *c
+	return &out
+}
+
+func (c *testNodeConfig) Init() error {
+	c.Hostname = c.NodeName + ".local"
+	return nil
+}
+
+func (c *testNodeConfig) Validate() error {
+	if c.Role != "worker" && c.Role != "control-plane" {
+		return fmt.Errorf("invalid role %q", c.Role)
+	}
+	return nil
+}
+
+func newTestScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	s.AddKnownTypeWithName(testGroupVersion.WithKind("ClusterConfig"), &testClusterConfig{})
+	s.AddKnownTypeWithName(testGroupVersion.WithKind("NodeConfig"), &testNodeConfig{})
+	s.AddKnownTypeWithName(testOldGroupVersion.WithKind("ClusterConfig"), &testClusterConfigV1alpha1{})
+	s.AddKnownTypeWithName(testInternalGroupVersion.WithKind("ClusterConfig"), &testClusterConfigInternal{})
+	mustAddConversionFunc(s, (*testClusterConfigV1alpha1)(nil), (*testClusterConfigInternal)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		in, out := a.(*testClusterConfigV1alpha1), b.(*testClusterConfigInternal)
+		out.Name, out.Port = in.ClusterName, in.Port
+		return nil
+	})
+	mustAddConversionFunc(s, (*testClusterConfigInternal)(nil), (*testClusterConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		in, out := a.(*testClusterConfigInternal), b.(*testClusterConfig)
+		out.Name, out.Port, out.Token = in.Name, in.Port, in.Token
+		return nil
+	})
+	s.AddTypeDefaultingFunc(&testClusterConfig{}, func(obj interface{}) {
+		c := obj.(*testClusterConfig)
+		if c.Port == 0 {
+			c.Port = 6443
+		}
+	})
+	s.AddTypeDefaultingFunc(&testNodeConfig{}, func(obj interface{}) {
+		c := obj.(*testNodeConfig)
+		if c.Role == "" {
+			c.Role = "worker"
+		}
+	})
+	return s
+}
+
+func mustAddConversionFunc(s *runtime.Scheme, a, b interface{}, fn conversion.ConversionFunc) {
+	if err := s.AddConversionFunc(a, b, fn); err != nil {
+		panic(err)
+	}
+}
+
+// writeTestConfig 写入临时配置文件, 返回路径
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return configPath
+}
+
+// executeTestCmd 执行命令, 返回标准输出
+func executeTestCmd(p *PhasesCmd, args ...string) (string, error) {
+	return executeTestCmdWithInput(p, nil, args...)
+}
+
+// executeTestCmdWithInput 以in作为标准输入执行命令, 返回标准输出
+func executeTestCmdWithInput(p *PhasesCmd, in io.Reader, args ...string) (string, error) {
+	cmd := p.Cmd()
+	if in != nil {
+		cmd.SetIn(in)
+	}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
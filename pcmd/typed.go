@@ -0,0 +1,141 @@
+package pcmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"github.com/s-z-z/phasext/util"
+	"github.com/s-z-z/phasext/workflow"
+)
+
+// TypedPhase phase直接接收类型为T的runner数据, 不需要断言
+// 通过TypedPhasesCmd[T]添加时, 数据类型与runner数据初始化函数在编译期保持一致
+type TypedPhase[T any] struct {
+	// name of the phase.
+	// Phase name should be unique among peer phases (phases belonging to
+	// the same workflow or phases belonging to the same parent phase).
+	Name string
+
+	// Aliases returns the aliases for the phase.
+	Aliases []string
+
+	// Short description of the phase.
+	Short string
+
+	// Long returns the long description of the phase.
+	Long string
+
+	// Example returns the example for the phase.
+	Example string
+
+	// Hidden define if the phase should be hidden in the workflow help.
+	Hidden bool
+
+	// RunAllSiblings allows to assign to a phase the responsibility to
+	// run all the sibling phases
+	// Nb. phase marked as RunAllSiblings can not have Run functions
+	RunAllSiblings bool
+
+	// Run: 优先级RunContext>Run
+	Run func(data T) error
+
+	// RunContext: 优先级RunContext>Run, 中断(SIGINT/SIGTERM)或超时后ctx被取消
+	// phase间传递结果: workflow.StoreFromContext(ctx)获取本次执行的Store
+	RunContext func(ctx context.Context, data T) error
+
+	// RunIf 执行条件: 返回false时跳过phase, reason为跳过原因, 记录在日志和执行报告中
+	RunIf func(data T) (ok bool, reason string, err error)
+
+	// Timeout phase执行超时时间, 0表示不超时; 重试时每次执行单独计时
+	Timeout time.Duration
+
+	// Retry 失败重试策略, nil表示不重试
+	Retry *workflow.RetryPolicy
+
+	// Rollback 撤销phase的操作: 后续phase失败时, 已执行phase的Rollback按逆序调用
+	Rollback func(data T) error
+
+	// DryRun dry-run模式下代替Run执行, 打印phase将要执行的操作
+	DryRun func(data T) error
+
+	// InheritFlags defines the list of flags that the cobra command generated for this phase should Inherit
+	// from local flags defined in the parent command / or additional flags defined in the phase runner.
+	InheritFlags []string
+
+	// Tags phase标签, 用于跨子树选择phase(--phase-tags, --skip-phase-tags), 选中phase时同时选中其子phase
+	Tags []string
+
+	// Dependencies is a list of phases that the specific phase depends on.
+	// 同级phase可直接写名称(如"ca"), 其他phase使用完整路径(如"certs/ca"), 以"/"开头强制按完整路径解析
+	Dependencies []string
+}
+
+func (p TypedPhase[T]) convert2workflowPhase() workflow.Phase {
+	return workflow.TypedPhase[T]{
+		Name:           p.Name,
+		Aliases:        p.Aliases,
+		Short:          p.Short,
+		Long:           p.Long,
+		Example:        p.Example,
+		Hidden:         p.Hidden,
+		RunAllSiblings: p.RunAllSiblings,
+		Run:            p.Run,
+		RunContext:     p.RunContext,
+		RunIfReason:    p.RunIf,
+		Timeout:        p.Timeout,
+		Retry:          p.Retry,
+		Rollback:       p.Rollback,
+		DryRun:         p.DryRun,
+		InheritFlags:   p.InheritFlags,
+		Tags:           p.Tags,
+		Dependencies:   p.Dependencies,
+	}.Phase()
+}
+
+// TypedPhasesCmd runner数据类型为T的PhasesCmd
+type TypedPhasesCmd[T any] struct {
+	*PhasesCmd
+}
+
+// Typed 设置类型为T的runner数据初始化函数, 返回TypedPhasesCmd[T]
+// 初始化函数与phase的数据类型不一致时编译失败
+func Typed[T any](p *PhasesCmd, initializer util.TypedRunnerDataInitializer[T]) *TypedPhasesCmd[T] {
+	p.runnerDataInitializer = func(cmd *cobra.Command, args []string) (workflow.RunData, error) {
+		return initializer(cmd, args)
+	}
+	return &TypedPhasesCmd[T]{PhasesCmd: p}
+}
+
+// TypedData WithData绑定的数据作为runner数据, phase直接接收data
+func TypedData[T WareHouse](p *PhasesCmd, data T) *TypedPhasesCmd[T] {
+	if p.data == nil {
+		klog.Fatalf("pcmd:TypedData: WithData must be set")
+	}
+	if p.data != WareHouse(data) {
+		klog.Fatalf("pcmd:TypedData: data is not the one bound by WithData")
+	}
+	return Typed(p, func(cmd *cobra.Command, args []string) (T, error) {
+		return data, nil
+	})
+}
+
+// CreateTyped 创建PhasesCmd并绑定数据(WithData), data同时作为runner数据, phase直接接收data
+func CreateTyped[T WareHouse](pf *PhaseCmdFactory, use string, data T, opts ...Option) *TypedPhasesCmd[T] {
+	p := pf.Create(use, append([]Option{WithData(data)}, opts...)...)
+	return TypedData(p, data)
+}
+
+// TypedArgs 命令行参数作为runner数据(默认行为), phase直接接收args
+func TypedArgs(p *PhasesCmd) *TypedPhasesCmd[[]string] {
+	return Typed(p, util.ArgsDataInitializer)
+}
+
+// AppendTypedPhases 添加TypedPhase[T]: 不需要断言
+func (t *TypedPhasesCmd[T]) AppendTypedPhases(phases ...TypedPhase[T]) {
+	for _, phase := range phases {
+		t.AppendPhases(phase.convert2workflowPhase())
+	}
+}
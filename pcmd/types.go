@@ -13,6 +13,7 @@ const (
 	DefaultConfigFlag      = "config"
 	DefaultGoValidate      = true
 	DefaultConfigWriteBack = false
+	DefaultCheckpointPath  = "./.checkpoint.json"
 )
 
 type DocumentMap map[schema.GroupVersionKind][]byte
@@ -0,0 +1,373 @@
+package pcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
+
+	boxutil "github.com/s-z-z/box/util"
+
+	"github.com/s-z-z/phasext/util"
+)
+
+// configProblem config validate发现的问题, line为0表示行号未知
+type configProblem struct {
+	line    int
+	kind    string
+	path    string
+	message string
+}
+
+func (c configProblem) format(configPath string) string {
+	s := configPath
+	if c.line > 0 {
+		s += ":" + strconv.Itoa(c.line)
+	}
+	if c.kind != "" {
+		s += ": " + c.kind
+	}
+	if c.path != "" {
+		s += ": " + c.path
+	}
+	return s + ": " + c.message
+}
+
+// yamlLineRegexp YAML错误中的行号, 如: yaml: line 3: mapping values are not allowed in this context
+// yaml.TypeError的每个错误没有"yaml: "前缀
+var yamlLineRegexp = regexp.MustCompile(`^(?:yaml: )?line (\d+):`)
+
+// yamlErrorLine YAML错误的行号, 未知时返回0
+func yamlErrorLine(err error) int {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		err = errors.New(typeErr.Errors[0])
+	}
+	m := yamlLineRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line
+}
+
+// strictFieldRegexp 严格解析错误中的字段路径, 如: unknown field "spec.foo"
+var strictFieldRegexp = regexp.MustCompile(`field "([^"]+)"`)
+
+// addConfigValidateCommand 添加config validate子命令: 校验配置文件, 一次报告全部问题, 有问题时返回错误(非0退出)
+func (p *PhasesCmd) addConfigValidateCommand() {
+	configPath := p.configPath
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the configuration file, reporting all the problems with their field paths and line numbers",
+		Args:  cobra.NoArgs,
+		// 校验失败不是用法错误
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := boxutil.GetAbsolutePath(configPath)
+			problems, err := p.validateConfigFile(path)
+			if err != nil {
+				return err
+			}
+			if len(problems) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", configPath)
+				return nil
+			}
+			for _, problem := range problems {
+				fmt.Fprintln(cmd.ErrOrStderr(), problem.format(configPath))
+			}
+			return errors.Errorf("%s is invalid: %d problem(s) found", configPath, len(problems))
+		},
+	}
+	validateCmd.Flags().StringVar(&configPath, p.configFlag, configPath, "Path to config file")
+	_ = validateCmd.MarkFlagFilename(p.configFlag, "yaml", "yml")
+	if configPath == "" {
+		_ = validateCmd.MarkFlagRequired(p.configFlag)
+	}
+	p.configCommand().AddCommand(validateCmd)
+}
+
+// validateConfigFile 校验配置文件的每个段:
+//  1. 分割: YAML语法, apiVersion和kind, kind不能重复
+//  2. 严格解析: 未知字段和重复字段; 旧版本转换为绑定数据对象的版本
+//  3. 默认值(scheme.Default)之后校验: validator和HasValidate, 不执行Init
+//
+// 返回全部问题, error只表示无法读取配置文件
+func (p *PhasesCmd) validateConfigFile(configPath string) ([]configProblem, error) {
+	b, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "pcmd:config:validate: %s", configPath)
+	}
+
+	var problems []configProblem
+	knownKinds := map[string]bool{}
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			// 语法错误之后无法继续解析
+			problems = append(problems, configProblem{line: yamlErrorLine(err), message: err.Error()})
+			break
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := doc.Content[0]
+
+		gvk, problem := documentGVK(root)
+		if problem != nil {
+			problems = append(problems, *problem)
+			continue
+		}
+		if knownKinds[gvk.Kind] {
+			problems = append(problems, configProblem{line: root.Line, kind: gvk.Kind, message: "kind is specified twice"})
+			continue
+		}
+		knownKinds[gvk.Kind] = true
+
+		problems = append(problems, p.validateDocument(root, gvk)...)
+	}
+
+	if p.data != nil && !knownKinds[p.gvk.Kind] {
+		problems = append(problems, configProblem{kind: p.gvk.Kind, message: fmt.Sprintf("missing document for %s", p.gvk.GroupVersion())})
+	}
+	return problems, nil
+}
+
+// documentGVK 段的apiVersion和kind
+func documentGVK(root *yaml.Node) (schema.GroupVersionKind, *configProblem) {
+	var apiVersion, kind string
+	if v := lookupNode(root, "apiVersion"); v != nil {
+		apiVersion = v.Value
+	}
+	if v := lookupNode(root, "kind"); v != nil {
+		kind = v.Value
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, &configProblem{line: root.Line, kind: kind, path: "apiVersion", message: err.Error()}
+	}
+	if gv.Group == "" || gv.Version == "" || kind == "" {
+		return schema.GroupVersionKind{}, &configProblem{line: root.Line, kind: kind, message: "kind and apiVersion is mandatory information that must be specified"}
+	}
+	return gv.WithKind(kind), nil
+}
+
+// validateDocument 严格解析和校验一个段
+func (p *PhasesCmd) validateDocument(root *yaml.Node, gvk schema.GroupVersionKind) []configProblem {
+	// converted 转换后字段路径使用目标版本的名称, 在原段中不一定存在
+	converted := false
+	problem := func(path, message string) configProblem {
+		line, found := nodeLine(root, path)
+		if converted && !found {
+			line = 0
+		}
+		return configProblem{line: line, kind: gvk.Kind, path: path, message: message}
+	}
+
+	if _, err := p.scheme.New(gvk); err != nil {
+		return []configProblem{problem("apiVersion", fmt.Sprintf("unknown configuration %s", gvk.GroupVersion()))}
+	}
+	b, err := yaml.Marshal(root)
+	if err != nil {
+		return []configProblem{problem("", err.Error())}
+	}
+
+	// 严格解析: 未知字段和重复字段
+	// 类型错误(如int字段的值为字符串)记录后删除该字段重新解析, 继续报告同一段中的其它问题
+	var problems []configProblem
+	typeErrPaths := map[string]bool{}
+	strict := jsonserializer.NewSerializerWithOptions(jsonserializer.DefaultMetaFactory, p.scheme, p.scheme, jsonserializer.SerializerOptions{Yaml: true, Strict: true})
+	for {
+		_, _, err := strict.Decode(b, &gvk, nil)
+		if err == nil {
+			break
+		}
+		if strictErr, ok := runtime.AsStrictDecodingError(err); ok {
+			for _, e := range strictErr.Errors() {
+				path := ""
+				if m := strictFieldRegexp.FindStringSubmatch(e.Error()); m != nil {
+					path = m[1]
+				}
+				problems = append(problems, problem(path, e.Error()))
+			}
+			break
+		}
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) || typeErr.Field == "" || typeErrPaths[typeErr.Field] {
+			return append(problems, problem("", err.Error()))
+		}
+		typeErrPaths[typeErr.Field] = true
+		problems = append(problems, problem(typeErr.Field, typeErrorMessage(typeErr)))
+		var removed bool
+		if b, removed = removeField(b, typeErr.Field); !removed {
+			return problems
+		}
+	}
+
+	// 旧版本转换为绑定数据对象的版本
+	target := gvk
+	for _, o := range p.dataObjects() {
+		if o.gvk.Group == gvk.Group && o.gvk.Kind == gvk.Kind && o.gvk != gvk {
+			if b, err = ConvertDocument(p.scheme, b, gvk, o.gvk); err != nil {
+				return append(problems, problem("apiVersion", err.Error()))
+			}
+			target = o.gvk
+			converted = true
+		}
+	}
+
+	obj, err := p.scheme.New(target)
+	if err != nil {
+		return append(problems, problem("apiVersion", err.Error()))
+	}
+	if _, _, err := p.codec().UniversalDeserializer().Decode(b, &target, obj); err != nil {
+		return append(problems, problem("", err.Error()))
+	}
+	p.scheme.Default(obj)
+
+	if !p.shouldValidate || p.v == nil {
+		return problems
+	}
+	if err := p.v.Struct(obj); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			return append(problems, problem("", err.Error()))
+		}
+		for _, fe := range validationErrs {
+			path := util.FieldPath(reflect.TypeOf(obj), fe.StructNamespace())
+			if typeErrPaths[path] {
+				// 已报告类型错误, 字段被删除
+				continue
+			}
+			problems = append(problems, problem(path, validationMessage(fe)))
+		}
+	}
+	if v, ok := obj.(HasValidate); ok {
+		if err := v.Validate(); err != nil {
+			problems = append(problems, problem("", err.Error()))
+		}
+	}
+	return problems
+}
+
+// validationMessage validator错误的描述, 如: failed on the "min=3" validation, got 1
+func validationMessage(fe validator.FieldError) string {
+	tag := fe.Tag()
+	if fe.Param() != "" {
+		tag += "=" + fe.Param()
+	}
+	message := fmt.Sprintf("failed on the %q validation", tag)
+	if value := fmt.Sprint(fe.Value()); value != "" {
+		message += ", got " + value
+	}
+	return message
+}
+
+// typeErrorMessage 类型错误的描述, 如: cannot unmarshal string into int
+func typeErrorMessage(e *json.UnmarshalTypeError) string {
+	return fmt.Sprintf("cannot unmarshal %s into %s", e.Value, e.Type)
+}
+
+// removeField 删除段中字段路径对应的字段, 字段不存在时返回false
+func removeField(b []byte, path string) ([]byte, bool) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil || len(doc.Content) == 0 {
+		return b, false
+	}
+	node := doc.Content[0]
+	tokens := pathTokenRegexp.FindAllString(path, -1)
+	for i, token := range tokens {
+		if node.Kind != yaml.MappingNode {
+			return b, false
+		}
+		found := false
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value != token {
+				continue
+			}
+			if i == len(tokens)-1 {
+				node.Content = append(node.Content[:j], node.Content[j+2:]...)
+			} else {
+				node = node.Content[j+1]
+			}
+			found = true
+			break
+		}
+		if !found {
+			return b, false
+		}
+	}
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return b, false
+	}
+	return out, true
+}
+
+// lookupNode 返回mapping中key对应的值
+func lookupNode(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// pathTokenRegexp 字段路径的每一部分: 字段名或[index]
+var pathTokenRegexp = regexp.MustCompile(`[^.\[\]]+|\[[^\]]*\]`)
+
+// nodeLine 字段路径在段中的行号; 字段不存在时(如required)返回最深的已存在字段的行号, found为false
+func nodeLine(root *yaml.Node, path string) (int, bool) {
+	node, line := root, root.Line
+	for _, token := range pathTokenRegexp.FindAllString(path, -1) {
+		key := token
+		if token[0] == '[' {
+			key = token[1 : len(token)-1]
+		}
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			found := false
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					line = node.Content[i].Line
+					node = node.Content[i+1]
+					found = true
+					break
+				}
+			}
+			if !found {
+				return line, false
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return line, false
+			}
+			node = node.Content[index]
+			line = node.Line
+		default:
+			return line, false
+		}
+	}
+	return line, true
+}
@@ -0,0 +1,58 @@
+package pcmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestValidateConfigFile(t *testing.T) {
+	var usecases = []struct {
+		name     string
+		config   string
+		expected []string
+	}{
+		{
+			name:   "valid configuration",
+			config: "apiVersion: test.phasext.io/v1\nkind: ClusterConfig\nname: foo\n",
+		},
+		{
+			name: "all the problems of a document are reported",
+			config: "apiVersion: test.phasext.io/v1\n" +
+				"kind: ClusterConfig\n" +
+				"port: x\n" +
+				"foo: bar\n",
+			expected: []string{
+				"config.yaml:3: ClusterConfig: port: cannot unmarshal string into int",
+				`config.yaml:4: ClusterConfig: foo: unknown field "foo"`,
+				`config.yaml:1: ClusterConfig: name: failed on the "required" validation`,
+			},
+		},
+		{
+			name:   "syntax error",
+			config: "apiVersion: test.phasext.io/v1\nkind: ClusterConfig\n  name: foo\n",
+			expected: []string{
+				"config.yaml:3: yaml: line 3: mapping values are not allowed in this context",
+				"config.yaml: ClusterConfig: missing document for test.phasext.io/v1",
+			},
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			configPath := writeTestConfig(t, u.config)
+			p := NewPhaseCmdFactory(newTestScheme(), validator.New()).Create("app", WithData(&testClusterConfig{}), WithSpecConfigPath(configPath))
+			problems, err := p.validateConfigFile(configPath)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var actual []string
+			for _, problem := range problems {
+				actual = append(actual, problem.format("config.yaml"))
+			}
+			if !reflect.DeepEqual(actual, u.expected) {
+				t.Errorf("\nproblems:\n\t%q\nexpected:\n\t%q\n", actual, u.expected)
+			}
+		})
+	}
+}
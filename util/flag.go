@@ -69,6 +69,11 @@ func getExportFields(o interface{}) ([]FieldProp, error) {
 	return exportFields, nil
 }
 
+// GetExportFields 返回o(struct指针)中export:"true"的字段
+func GetExportFields(o interface{}) ([]FieldProp, error) {
+	return getExportFields(o)
+}
+
 func parseExportTag(s string) ExportProp {
 	var ret ExportProp
 	s = strings.TrimSpace(s)
@@ -138,6 +143,9 @@ func AddExportFlags(cmd *cobra.Command, o any, specIncludes []string, flagKind F
 		if len(specIncludes) > 0 && !lo.Contains(specIncludes, f.FieldName) {
 			continue
 		}
+		if flagSet.Lookup(f.FlagName) != nil {
+			klog.Fatalf("Failed to export field %s of %T: flag %q already defined", f.FieldName, o, f.FlagName)
+		}
 		FlagSet(flagSet, f, bindAddr)
 	}
 }
@@ -179,7 +187,8 @@ func FlagSet(fs *pflag.FlagSet, f FieldProp, bindAddr bool) {
 }
 
 // AddConfigFlag 添加config flag
+// 不标记为必需: 子命令(如config print-defaults)不一定需要配置文件, 由使用者在加载配置前检查
 func AddConfigFlag(cmd *cobra.Command, cFlag string, configPathPtr *string) {
 	cmd.PersistentFlags().StringVar(configPathPtr, cFlag, *configPathPtr, "Path to config file")
-	_ = cmd.MarkPersistentFlagRequired(cFlag)
+	_ = cmd.MarkPersistentFlagFilename(cFlag, "yaml", "yml")
 }
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldPath 将validator的StructNamespace(如"Config.Spec.Items[0].Name")转换为t的JSON/YAML字段路径(如"spec.items[0].name")
+// 嵌入的struct和inline字段不出现在路径中; 无法解析的部分保持原样
+func FieldPath(t reflect.Type, namespace string) string {
+	segments := splitNamespace(namespace)
+	if len(segments) < 2 {
+		return namespace
+	}
+
+	var path []string
+	// 第一段为根类型名
+	for _, segment := range segments[1:] {
+		name, index := segment, ""
+		if i := strings.Index(segment, "["); i != -1 {
+			name, index = segment[:i], segment[i:]
+		}
+
+		t = indirect(t)
+		if t == nil || t.Kind() != reflect.Struct {
+			t = nil
+			path = append(path, segment)
+			continue
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			t = nil
+			path = append(path, segment)
+			continue
+		}
+
+		t = field.Type
+		for i := 0; i < strings.Count(index, "["); i++ {
+			if t = indirect(t); t == nil {
+				break
+			}
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				t = t.Elem()
+			default:
+				t = nil
+			}
+		}
+
+		jsonName, inline := jsonFieldName(field)
+		if inline && index == "" {
+			continue
+		}
+		path = append(path, jsonName+index)
+	}
+	return strings.Join(path, ".")
+}
+
+// splitNamespace 按"."分割, 忽略"[]"中的"."(如map的key)
+func splitNamespace(namespace string) []string {
+	var segments []string
+	depth, start := 0, 0
+	for i, c := range namespace {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				segments = append(segments, namespace[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(segments, namespace[start:])
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldPath(t *testing.T) {
+	typ := reflect.TypeOf(&RedactConfig{})
+
+	testCases := []struct {
+		namespace string
+		expected  string
+	}{
+		{"RedactConfig.Name", "name"},
+		{"RedactConfig.Meta.Kind", "kind"},
+		{"RedactConfig.Admin.Password", "admin.password"},
+		{"RedactConfig.Credentials[1].User", "credentials[1].user"},
+		{"RedactConfig.Registries[docker.io].User", "registries[docker.io].user"},
+		{"RedactConfig.Unknown.Field", "Unknown.Field"},
+		{"Name", "Name"},
+	}
+
+	for _, tc := range testCases {
+		actual := FieldPath(typ, tc.namespace)
+		if actual != tc.expected {
+			t.Errorf("namespace: %s, expected %s, got %s", tc.namespace, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"reflect"
+	"strings"
+)
+
+// RedactedValue 敏感字段的替换值
+const RedactedValue = "REDACTED"
+
+// Redact 根据o的类型, 将其JSON表示m中标记为sensitive:"true"的字段替换为RedactedValue
+// 支持嵌套的struct, 指针, slice, map和inline字段; 空值不替换
+func Redact(o any, m map[string]interface{}) {
+	redact(reflect.TypeOf(o), m)
+}
+
+func redact(t reflect.Type, v interface{}) {
+	if t == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if m, ok := v.(map[string]interface{}); ok {
+			redactStruct(t, m)
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := v.([]interface{}); ok {
+			for _, item := range items {
+				redact(t.Elem(), item)
+			}
+		}
+	case reflect.Map:
+		if m, ok := v.(map[string]interface{}); ok {
+			for _, item := range m {
+				redact(t.Elem(), item)
+			}
+		}
+	default:
+	}
+}
+
+func redactStruct(t reflect.Type, m map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name, inline := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+		if inline {
+			redact(field.Type, m)
+			continue
+		}
+
+		value, ok := m[name]
+		if !ok {
+			continue
+		}
+		if field.Tag.Get("sensitive") == "true" {
+			if value != nil && value != "" {
+				m[name] = RedactedValue
+			}
+			continue
+		}
+		redact(field.Type, value)
+	}
+}
+
+// jsonFieldName 字段在JSON中的名称, 以及是否inline(嵌入的struct或json:",inline")
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	name := getTag0String(tag)
+	if strings.Contains(tag, ",inline") || (name == "" && field.Anonymous) {
+		return "", true
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type Meta struct {
+	Kind string `json:"kind"`
+}
+
+type Credential struct {
+	User     string `json:"user"`
+	Password string `json:"password" sensitive:"true"`
+}
+
+type RedactConfig struct {
+	Meta        `json:",inline"`
+	Name        string                `json:"name"`
+	Token       string                `json:"token,omitempty" sensitive:"true"`
+	Admin       *Credential           `json:"admin"`
+	Credentials []Credential          `json:"credentials"`
+	Registries  map[string]Credential `json:"registries"`
+}
+
+func TestRedact(t *testing.T) {
+	c := &RedactConfig{
+		Meta:        Meta{Kind: "Config"},
+		Name:        "foo",
+		Admin:       &Credential{User: "admin", Password: "secret"},
+		Credentials: []Credential{{User: "a", Password: "pa"}, {User: "b"}},
+		Registries:  map[string]Credential{"docker.io": {User: "r", Password: "pr"}},
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	Redact(c, m)
+
+	expected := map[string]interface{}{
+		"kind":  "Config",
+		"name":  "foo",
+		"admin": map[string]interface{}{"user": "admin", "password": RedactedValue},
+		"credentials": []interface{}{
+			map[string]interface{}{"user": "a", "password": RedactedValue},
+			map[string]interface{}{"user": "b", "password": ""},
+		},
+		"registries": map[string]interface{}{
+			"docker.io": map[string]interface{}{"user": "r", "password": RedactedValue},
+		},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %v, got %v", expected, m)
+	}
+}
@@ -11,3 +11,10 @@ type RunnerDataInitializer func(cmd *cobra.Command, args []string) (workflow.Run
 func OnlyArgsDataInitializer(cmd *cobra.Command, args []string) (workflow.RunData, error) {
 	return args, nil
 }
+
+// TypedRunnerDataInitializer 返回类型为T的runner数据
+type TypedRunnerDataInitializer[T any] func(cmd *cobra.Command, args []string) (T, error)
+
+func ArgsDataInitializer(cmd *cobra.Command, args []string) ([]string, error) {
+	return args, nil
+}
@@ -0,0 +1,215 @@
+package workflow
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// Checkpoint defines the content of the checkpoint file, that keeps track of the phases
+// completed by a workflow so that an interrupted or failed execution can be resumed.
+type Checkpoint struct {
+	// Key identifies the configuration used by the workflow that recorded the checkpoint.
+	Key string `json:"key"`
+
+	// CompletedPhases is the list of the full names of the completed phases, in completion order.
+	CompletedPhases []string `json:"completedPhases"`
+
+	// Store is the content of the store of the workflow when the last phase was completed.
+	Store map[string]json.RawMessage `json:"store,omitempty"`
+}
+
+// LoadCheckpoint reads a checkpoint file.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read checkpoint file %s", path)
+	}
+	c := &Checkpoint{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode checkpoint file %s", path)
+	}
+	return c, nil
+}
+
+// checkpointRecorder records the completed phases into the checkpoint file while the
+// workflow is running.
+type checkpointRecorder struct {
+	mu        sync.Mutex
+	path      string
+	state     Checkpoint
+	completed map[string]bool
+
+	// conditionFalse are the phases whose run condition was not satisfied in this execution;
+	// they are not recorded as completed, but they don't prevent removing the checkpoint file.
+	conditionFalse map[string]bool
+}
+
+// newCheckpointRecorder initializes the checkpoint recorder according to RunnerOptions.
+// If RunnerOptions.Resume is set, the phases recorded by a previous checkpoint with the same key
+// are considered completed; otherwise, or if the key doesn't match, the checkpoint starts empty.
+func newCheckpointRecorder(options RunnerOptions) (*checkpointRecorder, error) {
+	if options.CheckpointFile == "" {
+		if options.Resume {
+			return nil, errors.New("resume requires a checkpoint file")
+		}
+		return nil, nil
+	}
+
+	c := &checkpointRecorder{
+		path:      options.CheckpointFile,
+		state:     Checkpoint{Key: options.CheckpointKey},
+		completed: map[string]bool{},
+
+		conditionFalse: map[string]bool{},
+	}
+
+	if !options.Resume {
+		return c, nil
+	}
+
+	previous, err := LoadCheckpoint(options.CheckpointFile)
+	if os.IsNotExist(errors.Cause(err)) {
+		klog.V(1).Infof("checkpoint file %s does not exist, running all the phases", options.CheckpointFile)
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if previous.Key != options.CheckpointKey {
+		klog.Warningf("checkpoint file %s was recorded with a different configuration, running all the phases", options.CheckpointFile)
+		return c, nil
+	}
+
+	c.state.CompletedPhases = previous.CompletedPhases
+	c.state.Store = previous.Store
+	for _, name := range previous.CompletedPhases {
+		c.completed[name] = true
+	}
+	return c, nil
+}
+
+// isCompleted returns true if the phase was completed by a previous execution of the workflow.
+func (c *checkpointRecorder) isCompleted(p *phaseRunner) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.completed[p.generatedName]
+}
+
+// restoreStore returns a store with the content recorded by the previous execution, if resuming.
+func (c *checkpointRecorder) restoreStore() *Store {
+	s := NewStore()
+	if c == nil {
+		return s
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, v := range c.state.Store {
+		s.values[k] = v
+	}
+	return s
+}
+
+// record adds the phase to the list of completed phases and persists the checkpoint file,
+// together with the current content of the store.
+// Nb. the store is read while holding the lock, so concurrent phases can't persist an older
+// content of the store after a newer one.
+func (c *checkpointRecorder) record(p *phaseRunner, s *Store) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.completed[p.generatedName] {
+		return nil
+	}
+	values, err := s.marshal()
+	if err != nil {
+		return errors.Wrapf(err, "failed to record checkpoint for phase %s", p.generatedName)
+	}
+	c.completed[p.generatedName] = true
+	c.state.CompletedPhases = append(c.state.CompletedPhases, p.generatedName)
+	c.state.Store = values
+	return c.save()
+}
+
+// skipConditionFalse records that the run condition of the phase was not satisfied.
+func (c *checkpointRecorder) skipConditionFalse(p *phaseRunner) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conditionFalse[p.generatedName] = true
+}
+
+// forget removes the phase from the list of completed phases, e.g. after the phase is rolled back.
+func (c *checkpointRecorder) forget(p *phaseRunner) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.completed[p.generatedName] {
+		return nil
+	}
+	delete(c.completed, p.generatedName)
+	completedPhases := []string{}
+	for _, name := range c.state.CompletedPhases {
+		if name != p.generatedName {
+			completedPhases = append(completedPhases, name)
+		}
+	}
+	c.state.CompletedPhases = completedPhases
+	return c.save()
+}
+
+// finalize removes the checkpoint file if all the phases in the workflow are completed,
+// or their run condition was not satisfied.
+func (c *checkpointRecorder) finalize(phaseRunners []*phaseRunner) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range phaseRunners {
+		if !c.completed[p.generatedName] && !c.conditionFalse[p.generatedName] {
+			return nil
+		}
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove checkpoint file %s", c.path)
+	}
+	return nil
+}
+
+// save writes the checkpoint file atomically.
+func (c *checkpointRecorder) save() error {
+	b, err := json.MarshalIndent(c.state, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode checkpoint")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return errors.Wrapf(err, "failed to write checkpoint file %s", c.path)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "failed to write checkpoint file %s", c.path)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write checkpoint file %s", c.path)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return errors.Wrapf(err, "failed to write checkpoint file %s", c.path)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRunCheckpoint(t *testing.T) {
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	fail := true
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder1("foo", nil, phaseBuilder1("bar", nil)),
+			{Name: "baz", Run: func(data RunData) error {
+				if fail {
+					return runFails(data)
+				}
+				return runBuilder("baz")(data)
+			}},
+			phaseBuilder1("qux", nil),
+		},
+		Options: RunnerOptions{CheckpointFile: checkpointFile, CheckpointKey: "v1"},
+	}
+
+	// first execution fails, the completed phases are recorded
+	callstack = []string{}
+	if err := w.Run([]string{}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	checkpoint, err := LoadCheckpoint(checkpointFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := &Checkpoint{Key: "v1", CompletedPhases: []string{"foo", "foo/bar"}}
+	if !reflect.DeepEqual(checkpoint, expected) {
+		t.Errorf("\ncheckpoint:\n\t%v\nexpected:\n\t%v\n", checkpoint, expected)
+	}
+
+	// resuming with a different configuration runs all the phases
+	fail = true
+	callstack = []string{}
+	w.Options.Resume = true
+	w.Options.CheckpointKey = "v2"
+	if err := w.Run([]string{}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !reflect.DeepEqual(callstack, []string{"foo", "bar"}) {
+		t.Errorf("expected all the phases to run, got %v", callstack)
+	}
+
+	// resuming skips the completed phases, and the checkpoint is removed at the end
+	fail = false
+	callstack = []string{}
+	if err := w.Run([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(callstack, []string{"baz", "qux"}) {
+		t.Errorf("expected only the remaining phases to run, got %v", callstack)
+	}
+	if _, err := os.Stat(checkpointFile); !os.IsNotExist(err) {
+		t.Errorf("expected checkpoint file to be removed, got %v", err)
+	}
+}
+
+func TestRunCheckpointRequiresFile(t *testing.T) {
+	var w = Runner{
+		Phases:  []Phase{phaseBuilder1("foo", nil)},
+		Options: RunnerOptions{Resume: true},
+	}
+	if err := w.Run([]string{}); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestRunCheckpointSkippedPhases(t *testing.T) {
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	skip := map[string]bool{"foo": true}
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder1("foo", nil),
+			phaseBuilder1("bar", nil),
+			{Name: "baz", Run: runBuilder("baz"), RunIf: func(data RunData) (bool, error) { return false, nil }},
+		},
+		Options: RunnerOptions{
+			CheckpointFile: checkpointFile,
+			BeforePhase: func(phase string) error {
+				if skip[phase] {
+					return ErrSkipPhase
+				}
+				return nil
+			},
+		},
+	}
+
+	// phases skipped by the user or by their run condition are not recorded as completed
+	callstack = []string{}
+	if err := w.Run([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkpoint, err := LoadCheckpoint(checkpointFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"bar"}; !reflect.DeepEqual(checkpoint.CompletedPhases, expected) {
+		t.Errorf("\ncompleted phases:\n\t%v\nexpected:\n\t%v\n", checkpoint.CompletedPhases, expected)
+	}
+
+	// resuming runs the skipped phase, and the checkpoint is removed at the end
+	skip = map[string]bool{}
+	callstack = []string{}
+	w.Options.Resume = true
+	if err := w.Run([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(callstack, []string{"foo"}) {
+		t.Errorf("expected only the skipped phase to run, got %v", callstack)
+	}
+	if _, err := os.Stat(checkpointFile); !os.IsNotExist(err) {
+		t.Errorf("expected checkpoint file to be removed, got %v", err)
+	}
+}
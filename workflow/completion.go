@@ -0,0 +1,46 @@
+package workflow
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// completePhaseNames completes the full names of the phases of the workflow, including the
+// paths using aliases, for the flags selecting phases; comma separated lists and negated
+// selectors are supported.
+func (e *Runner) completePhaseNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// completes only the last element of the list, keeping the previous ones
+	prefix := ""
+	if pos := strings.LastIndex(toComplete, ","); pos != -1 {
+		prefix, toComplete = toComplete[:pos+1], toComplete[pos+1:]
+	}
+	if strings.HasPrefix(toComplete, selectorNegation) {
+		prefix += selectorNegation
+		toComplete = strings.TrimPrefix(toComplete, selectorNegation)
+	}
+
+	// completes the full names of the phases first, and then the paths using aliases
+	var completions []string
+	for _, aliases := range []bool{false, true} {
+		e.visitAll(func(p *phaseRunner) error {
+			for i, path := range p.paths {
+				if (i > 0) == aliases && strings.HasPrefix(path, strings.ToLower(toComplete)) {
+					completions = append(completions, prefix+path)
+				}
+			}
+			return nil
+		})
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerPhaseNamesCompletion registers the completion of the phase names for the given flags.
+func (e *Runner) registerPhaseNamesCompletion(cmd *cobra.Command, flags ...string) {
+	for _, f := range flags {
+		if err := cmd.RegisterFlagCompletionFunc(f, e.completePhaseNames); err != nil {
+			klog.Warningf("failed to register completion for flag %s: %v", f, err)
+		}
+	}
+}
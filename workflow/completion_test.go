@@ -0,0 +1,113 @@
+package workflow
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestPhasesFlags(t *testing.T) {
+	callstack = []string{}
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder1("foo", nil, phaseBuilder1("bar", nil)),
+			phaseBuilder1("baz", nil),
+			phaseBuilder1("qux", nil),
+		},
+	}
+	cmd := &cobra.Command{
+		Use: "init",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return w.Run(args)
+		},
+	}
+	w.BindToCommand(cmd)
+
+	cmd.SetArgs([]string{"--phases", "foo/bar", "--only-phases", "qux"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(callstack, []string{"bar", "qux"}) {
+		t.Errorf("\ncallstack:\n\t%v\nexpected:\n\t%v\n", callstack, []string{"bar", "qux"})
+	}
+}
+
+func TestPhasesFlagsCompletion(t *testing.T) {
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder("foo", phaseBuilder("bar")),
+			phaseBuilder("baz"),
+		},
+	}
+	cmd := &cobra.Command{Use: "init", Run: func(cmd *cobra.Command, args []string) {}}
+	w.BindToCommand(cmd)
+
+	var usecases = []struct {
+		flag       string
+		toComplete string
+		expected   []string
+	}{
+		{flag: "--skip-phases", toComplete: "", expected: []string{"foo", "foo/bar", "baz"}},
+		{flag: "--only-phases", toComplete: "foo/", expected: []string{"foo/bar"}},
+		{flag: "--phases", toComplete: "foo,b", expected: []string{"foo,baz"}},
+		{flag: "--skip-phases", toComplete: "!f", expected: []string{"!foo", "!foo/bar"}},
+	}
+	for _, u := range usecases {
+		t.Run(u.flag+"="+u.toComplete, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{cobra.ShellCompRequestCmd, u.flag, u.toComplete})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			// the completion output ends with the directive, e.g. ":4"
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			actual := lines[:len(lines)-1]
+			if !reflect.DeepEqual(actual, u.expected) {
+				t.Errorf("\nactual:\n\t%v\nexpected:\n\t%v\n", actual, u.expected)
+			}
+		})
+	}
+}
+
+func TestPhaseAliasesCompletion(t *testing.T) {
+	var w = Runner{
+		Phases: []Phase{
+			{Name: "certs", Aliases: []string{"certificates"}, Phases: []Phase{
+				{Name: "ca", Aliases: []string{"root-ca"}, Run: runBuilder("ca")},
+			}},
+		},
+	}
+	cmd := &cobra.Command{
+		Use: "init",
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{"node1"}, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	w.BindToCommand(cmd)
+
+	completions, _ := w.completePhaseNames(cmd, nil, "cert")
+	expected := []string{"certs", "certs/ca", "certificates", "certs/root-ca", "certificates/ca", "certificates/root-ca"}
+	if !reflect.DeepEqual(completions, expected) {
+		t.Errorf("\nactual:\n\t%v\nexpected:\n\t%v\n", completions, expected)
+	}
+
+	// aliases can be used for selecting phases
+	callstack = []string{}
+	w.Options = RunnerOptions{FilterPhases: []string{"certificates/root-ca"}}
+	if err := w.Run([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(callstack, []string{"ca"}) {
+		t.Errorf("expected callstack [ca], got %v", callstack)
+	}
+
+	// phase subcommands complete args like the parent command
+	phaseCmd, _, _ := cmd.Find([]string{"phase", "certs", "ca"})
+	if phaseCmd.ValidArgsFunction == nil {
+		t.Error("expected phase subcommand to have ValidArgsFunction")
+	}
+}
@@ -0,0 +1,153 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// resolveDependency returns the phase referenced by the given dependency of a phase, if any.
+// A dependency is the path of a phase relative to the parent of the phase, e.g. "ca" for
+// a sibling phase, or the full path of the phase, e.g. "certs/ca"; a leading separator,
+// e.g. "/certs/ca", forces the dependency to be resolved as a full path.
+func (e *Runner) resolveDependency(p *phaseRunner, dep string) *phaseRunner {
+	dep = strings.ToLower(dep)
+	if !strings.HasPrefix(dep, phaseSeparator) && p.parent != nil {
+		if r := e.lookupPhaseRunner(p.parent.generatedName + phaseSeparator + dep); r != nil {
+			return r
+		}
+	}
+	return e.lookupPhaseRunner(strings.TrimPrefix(dep, phaseSeparator))
+}
+
+// hasDependencies returns true if the phase with the given full name, or any of its nested phases, has Dependencies.
+func (e *Runner) hasDependencies(name string) bool {
+	for _, p := range e.phaseRunners {
+		if p.generatedName != name && !strings.HasPrefix(p.generatedName, name+phaseSeparator) {
+			continue
+		}
+		if len(p.Dependencies) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDependencies checks that the Dependencies of all the phases in the workflow reference
+// existing phases, and that there are no dependency cycles.
+// Nb. nested phases implicitly depend on their parent phase.
+func (e *Runner) ValidateDependencies() error {
+	e.prepareForExecution()
+
+	var errs []error
+	graph := map[*phaseRunner][]*phaseRunner{}
+	for _, p := range e.phaseRunners {
+		if p.parent != nil {
+			graph[p] = append(graph[p], p.parent)
+		}
+		for _, dep := range p.Dependencies {
+			r := e.resolveDependency(p, dep)
+			if r == nil {
+				errs = append(errs, errors.Errorf("phase %q depends on unknown phase %q", p.generatedName, dep))
+				continue
+			}
+			if r == p {
+				errs = append(errs, errors.Errorf("phase %q depends on itself", p.generatedName))
+				continue
+			}
+			graph[p] = append(graph[p], r)
+		}
+	}
+
+	// detects cycles with a depth-first visit of the graph
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[*phaseRunner]int{}
+	var path []*phaseRunner
+	var visit func(p *phaseRunner) error
+	visit = func(p *phaseRunner) error {
+		switch state[p] {
+		case visiting:
+			// the cycle starts from the first occurrence of p in the current path
+			var cycle []string
+			for i := len(path) - 1; i >= 0; i-- {
+				if path[i] == p {
+					for _, c := range path[i:] {
+						cycle = append(cycle, c.generatedName)
+					}
+					break
+				}
+			}
+			cycle = append(cycle, p.generatedName)
+			return errors.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		case visited:
+			return nil
+		}
+		state[p] = visiting
+		path = append(path, p)
+		for _, d := range graph[p] {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[p] = visited
+		return nil
+	}
+	for _, p := range e.phaseRunners {
+		if err := visit(p); err != nil {
+			errs = append(errs, err)
+			break
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// resolveDependencies checks that the dependencies of all the phases to be run are satisfied
+// by phases executed before them, and returns the list of phases each phase depends on.
+func (e *Runner) resolveDependencies(phaseRunFlags map[string]bool) (map[*phaseRunner][]*phaseRunner, error) {
+	phaseDependencies, missedDeps := e.computeDependencies(phaseRunFlags)
+	if len(missedDeps) > 0 {
+		var msg strings.Builder
+		msg.WriteString("unresolved dependencies:")
+		for _, p := range e.phaseRunners {
+			if missedPhases, ok := missedDeps[p]; ok {
+				msg.WriteString(fmt.Sprintf("\n\tmissing %v phase(s) needed by %q phase", missedPhases, p.generatedName))
+			}
+		}
+		return nil, errors.New(msg.String())
+	}
+	return phaseDependencies, nil
+}
+
+// computeDependencies returns, for each phase to be run, the list of phases it depends on
+// and the full names of the dependencies that are not satisfied by phases executed before it.
+func (e *Runner) computeDependencies(phaseRunFlags map[string]bool) (map[*phaseRunner][]*phaseRunner, map[*phaseRunner][]string) {
+	phaseDependencies := make(map[*phaseRunner][]*phaseRunner)
+	missedDeps := make(map[*phaseRunner][]string)
+	visited := make(map[*phaseRunner]bool)
+	for _, p := range e.phaseRunners {
+		if run, ok := phaseRunFlags[p.generatedName]; !run || !ok {
+			continue
+		}
+		for _, dep := range p.Phase.Dependencies {
+			r := e.resolveDependency(p, dep)
+			if r == nil {
+				missedDeps[p] = append(missedDeps[p], dep)
+				continue
+			}
+			if !visited[r] {
+				missedDeps[p] = append(missedDeps[p], r.generatedName)
+				continue
+			}
+			phaseDependencies[p] = append(phaseDependencies[p], r)
+		}
+		visited[p] = true
+	}
+	return phaseDependencies, missedDeps
+}
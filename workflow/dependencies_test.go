@@ -0,0 +1,199 @@
+package workflow
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	var usecases = []struct {
+		name          string
+		phases        []Phase
+		expectedError string
+	}{
+		{
+			name: "full and relative paths are resolved",
+			phases: []Phase{
+				phaseBuilder("certs", phaseBuilder("ca"), Phase{Name: "apiserver", Dependencies: []string{"ca"}}),
+				phaseBuilder("kubeconfig", Phase{Name: "ca", Dependencies: []string{"certs/ca", "/certs"}}),
+			},
+		},
+		{
+			name:          "unknown dependency",
+			phases:        []Phase{{Name: "foo", Dependencies: []string{"bar"}}},
+			expectedError: `phase "foo" depends on unknown phase "bar"`,
+		},
+		{
+			name: "relative paths are resolved only among siblings",
+			phases: []Phase{
+				phaseBuilder("certs", phaseBuilder("ca")),
+				phaseBuilder("kubeconfig", Phase{Name: "admin", Dependencies: []string{"ca"}}),
+			},
+			expectedError: `phase "kubeconfig/admin" depends on unknown phase "ca"`,
+		},
+		{
+			name: "dependency cycle",
+			phases: []Phase{
+				{Name: "foo", Dependencies: []string{"baz"}},
+				{Name: "bar", Dependencies: []string{"foo"}},
+				{Name: "baz", Dependencies: []string{"bar"}},
+			},
+			expectedError: "dependency cycle: foo -> baz -> bar -> foo",
+		},
+		{
+			name: "dependency cycle with nested phases",
+			phases: []Phase{
+				{Name: "foo", Dependencies: []string{"foo/bar"}, Phases: []Phase{phaseBuilder("bar")}},
+			},
+			expectedError: "dependency cycle: foo -> foo/bar -> foo",
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			var w = Runner{Phases: u.phases}
+			err := w.ValidateDependencies()
+			if u.expectedError == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), u.expectedError) {
+				t.Errorf("expected error %q, got %v", u.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestRunDependencies(t *testing.T) {
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder1("certs", nil, phaseBuilder1("ca", nil)),
+			phaseBuilder1("kubeconfig", nil,
+				Phase{Name: "ca", Run: runBuilder("kubeconfig/ca"), Dependencies: []string{"certs/ca"}},
+			),
+		},
+	}
+
+	var usecases = []struct {
+		name          string
+		options       RunnerOptions
+		expectedError string
+	}{
+		{
+			name: "dependencies with the same short name don't collide",
+		},
+		{
+			name:          "errors name the full paths on both sides",
+			options:       RunnerOptions{SkipPhases: []string{"certs/ca"}},
+			expectedError: `missing [certs/ca] phase(s) needed by "kubeconfig/ca" phase`,
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			w.Options = u.options
+			err := w.Run([]string{})
+			if u.expectedError == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), u.expectedError) {
+				t.Errorf("expected error %q, got %v", u.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestRunIncludeDependencies(t *testing.T) {
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder1("certs", nil,
+				phaseBuilder1("ca", nil),
+				Phase{Name: "apiserver", Run: runBuilder("certs/apiserver"), Dependencies: []string{"ca"}},
+			),
+			phaseBuilder1("etcd", nil),
+			Phase{Name: "kubeconfig", Run: runBuilder("kubeconfig"), Dependencies: []string{"certs/apiserver"}},
+			Phase{Name: "controlplane", Run: runBuilder("controlplane"), Dependencies: []string{"kubeconfig", "etcd"}},
+		},
+	}
+
+	var usecases = []struct {
+		name              string
+		options           RunnerOptions
+		expectedCallstack []string
+		expectedIncluded  []string
+		expectedError     bool
+	}{
+		{
+			name:          "dependencies are not included by default",
+			options:       RunnerOptions{FilterPhases: []string{"controlplane"}},
+			expectedError: true,
+		},
+		{
+			name:              "transitive dependencies are included in execution order",
+			options:           RunnerOptions{FilterPhases: []string{"controlplane"}, IncludeDependencies: true},
+			expectedCallstack: []string{"ca", "certs/apiserver", "etcd", "kubeconfig", "controlplane"},
+			expectedIncluded:  []string{"certs/ca", "certs/apiserver", "etcd", "kubeconfig"},
+		},
+		{
+			name:          "skipped phases are not included",
+			options:       RunnerOptions{FilterPhases: []string{"controlplane"}, SkipPhases: []string{"etcd"}, IncludeDependencies: true},
+			expectedError: true,
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			callstack = []string{}
+			w.Options = u.options
+			err := w.Run([]string{})
+			if (err != nil) != u.expectedError {
+				t.Fatalf("expected error: %v, got: %v", u.expectedError, err)
+			}
+			if u.expectedError {
+				return
+			}
+			if !reflect.DeepEqual(callstack, u.expectedCallstack) {
+				t.Errorf("\ncallstack:\n\t%v\nexpected:\n\t%v\n", callstack, u.expectedCallstack)
+			}
+			var included []string
+			for _, p := range w.Report().Phases {
+				if p.IncludedAsDependency {
+					included = append(included, p.Name)
+				}
+			}
+			if !reflect.DeepEqual(included, u.expectedIncluded) {
+				t.Errorf("\nincluded:\n\t%v\nexpected:\n\t%v\n", included, u.expectedIncluded)
+			}
+		})
+	}
+}
+
+func TestBindToCommandWithDeps(t *testing.T) {
+	callstack = []string{}
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder1("foo", nil),
+			phaseBuilder1("bar", nil),
+			{Name: "baz", Run: runBuilder("baz"), Dependencies: []string{"foo"}},
+		},
+	}
+	cmd := &cobra.Command{Use: "init"}
+	w.BindToCommand(cmd)
+
+	if c, _, _ := cmd.Find([]string{"phase", "bar"}); c.Flags().Lookup("with-deps") != nil {
+		t.Error("expected no with-deps flag for a phase without dependencies")
+	}
+
+	cmd.SetArgs([]string{"phase", "baz", "--with-deps"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(callstack, []string{"foo", "baz"}) {
+		t.Errorf("\ncallstack:\n\t%v\nexpected:\n\t%v\n", callstack, []string{"foo", "baz"})
+	}
+}
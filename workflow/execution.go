@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// execution holds the state of a single execution of the workflow managed by the Runner.
+type execution struct {
+	// ctx is the context of the execution.
+	ctx context.Context
+
+	// data is the runtime data shared among all the phases.
+	data RunData
+
+	// checkpoint records the completed phases, if a checkpoint file is defined.
+	checkpoint *checkpointRecorder
+
+	// store is the key/value store shared among all the phases.
+	store *Store
+
+	// skipLogVerbosity is the klog verbosity of the log lines for skipped phases.
+	skipLogVerbosity klog.Level
+
+	// observers are notified about the lifecycle events of the phases.
+	observers []Observer
+
+	// notifyMu serializes the notifications to the observers.
+	notifyMu sync.Mutex
+
+	// mu protects the fields below, that could be updated by concurrent phases.
+	mu sync.Mutex
+
+	// executed is the list of phases whose action was executed, in completion order.
+	executed []*phaseRunner
+}
+
+// markExecuted records that the action of the given phase was executed.
+func (x *execution) markExecuted(p *phaseRunner) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.executed = append(x.executed, p)
+}
@@ -0,0 +1,166 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// GraphFormat defines the format of the graph of the workflow.
+type GraphFormat string
+
+const (
+	// GraphFormatDOT renders the graph of the workflow using the Graphviz DOT language.
+	GraphFormatDOT GraphFormat = "dot"
+
+	// GraphFormatMermaid renders the graph of the workflow as a Mermaid flowchart.
+	GraphFormatMermaid GraphFormat = "mermaid"
+)
+
+// WriteGraph renders the phases of the workflow as a graph in the given format.
+// Nested phases are linked to their parent phase with solid edges, while Dependencies are
+// rendered as dashed edges from the dependency to the phase depending on it.
+// Conditional phases (with RunIf or RunIfReason) are rendered as diamonds, hidden phases are gray and
+// RunAllSiblings phases have a dotted border.
+func (e *Runner) WriteGraph(w io.Writer, format GraphFormat) error {
+	e.prepareForExecution()
+
+	var b strings.Builder
+	switch format {
+	case GraphFormatDOT:
+		e.writeDOT(&b)
+	case GraphFormatMermaid:
+		e.writeMermaid(&b)
+	default:
+		return errors.Errorf("unknown graph format %q, must be one of: %s, %s", format, GraphFormatDOT, GraphFormatMermaid)
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return errors.Wrap(err, "failed to write the graph of the workflow")
+	}
+	return nil
+}
+
+// writeDOT renders the graph of the workflow using the Graphviz DOT language.
+func (e *Runner) writeDOT(b *strings.Builder) {
+	b.WriteString("digraph workflow {\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, p := range e.phaseRunners {
+		attrs := []string{fmt.Sprintf("label=%q", cleanName(p.Name))}
+		if p.conditional() {
+			attrs = append(attrs, "shape=diamond")
+		}
+		if p.Hidden {
+			attrs = append(attrs, "color=gray", "fontcolor=gray")
+		}
+		if p.RunAllSiblings {
+			attrs = append(attrs, "style=dotted")
+		}
+		fmt.Fprintf(b, "  %q [%s];\n", p.generatedName, strings.Join(attrs, ", "))
+	}
+	e.visitEdges(func(from, to *phaseRunner, dependency bool) {
+		if dependency {
+			fmt.Fprintf(b, "  %q -> %q [style=dashed];\n", from.generatedName, to.generatedName)
+			return
+		}
+		fmt.Fprintf(b, "  %q -> %q;\n", from.generatedName, to.generatedName)
+	})
+	b.WriteString("}\n")
+}
+
+// writeMermaid renders the graph of the workflow as a Mermaid flowchart.
+func (e *Runner) writeMermaid(b *strings.Builder) {
+	// Mermaid node ids can't contain the phase separator, so phases are identified by their position
+	ids := map[*phaseRunner]string{}
+	for i, p := range e.phaseRunners {
+		ids[p] = fmt.Sprintf("p%d", i)
+	}
+
+	b.WriteString("flowchart TD\n")
+	for _, p := range e.phaseRunners {
+		label := strings.ReplaceAll(cleanName(p.Name), `"`, "#quot;")
+		node := fmt.Sprintf("[%q]", label)
+		if p.conditional() {
+			node = fmt.Sprintf("{%q}", label)
+		}
+		var classes []string
+		if p.Hidden {
+			classes = append(classes, "hidden")
+		}
+		if p.RunAllSiblings {
+			classes = append(classes, "runAllSiblings")
+		}
+		class := ""
+		if len(classes) > 0 {
+			class = ":::" + strings.Join(classes, " ")
+		}
+		fmt.Fprintf(b, "  %s%s%s\n", ids[p], node, class)
+	}
+	e.visitEdges(func(from, to *phaseRunner, dependency bool) {
+		if dependency {
+			fmt.Fprintf(b, "  %s -.-> %s\n", ids[from], ids[to])
+			return
+		}
+		fmt.Fprintf(b, "  %s --> %s\n", ids[from], ids[to])
+	})
+	b.WriteString("  classDef hidden stroke:#999,color:#999\n")
+	b.WriteString("  classDef runAllSiblings stroke-dasharray: 3 3\n")
+}
+
+// visitEdges visits the edges of the graph of the workflow, first the ones linking
+// nested phases to their parent and then the ones for Dependencies.
+// Nb. unknown dependencies are ignored; use ValidateDependencies for checking them.
+func (e *Runner) visitEdges(fn func(from, to *phaseRunner, dependency bool)) {
+	for _, p := range e.phaseRunners {
+		if p.parent != nil {
+			fn(p.parent, p, false)
+		}
+	}
+	for _, p := range e.phaseRunners {
+		for _, dep := range p.Dependencies {
+			if r := e.resolveDependency(p, dep); r != nil {
+				fn(r, p, true)
+			}
+		}
+	}
+}
+
+// EnableGraphCommand makes BindToCommand add a hidden "phase graph" subcommand,
+// that prints the graph of the workflow.
+// Please note that this command needs to be done before BindToCommand.
+func (e *Runner) EnableGraphCommand() {
+	e.graphCommand = true
+}
+
+// addGraphCommand adds the hidden graph subcommand to the phase command, unless a phase
+// with the same name already exists.
+func (e *Runner) addGraphCommand(phaseCommand *cobra.Command) {
+	for _, c := range phaseCommand.Commands() {
+		if c.Name() == "graph" {
+			klog.Warningf("phase graph subcommand not added, a phase with the same name already exists")
+			return
+		}
+	}
+
+	var format string
+	graphCmd := &cobra.Command{
+		Use:    "graph",
+		Short:  "Print the graph of the phases of the workflow",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		// the graph does not depend on the RunData, so the PersistentPreRunE of the
+		// parent commands, e.g. loading the configuration, is overridden
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return e.WriteGraph(cmd.OutOrStdout(), GraphFormat(format))
+		},
+	}
+	graphCmd.Flags().StringVar(&format, "format", string(GraphFormatDOT), fmt.Sprintf("Format of the graph, one of: %s, %s", GraphFormatDOT, GraphFormatMermaid))
+	phaseCommand.AddCommand(graphCmd)
+}
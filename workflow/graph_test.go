@@ -0,0 +1,104 @@
+package workflow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func graphTestRunner() *Runner {
+	return &Runner{
+		Phases: []Phase{
+			phaseBuilder("foo",
+				phaseBuilder1("bar", runConditionTrue),
+				Phase{Name: "all", RunAllSiblings: true},
+			),
+			phaseBuilder3("baz", true),
+			{Name: "qux", Dependencies: []string{"foo/bar", "unknown"}},
+		},
+	}
+}
+
+func TestWriteGraph(t *testing.T) {
+	var usecases = []struct {
+		name     string
+		format   GraphFormat
+		expected string
+	}{
+		{
+			name:   "dot",
+			format: GraphFormatDOT,
+			expected: "digraph workflow {\n" +
+				"  node [shape=box];\n" +
+				"  \"foo\" [label=\"foo\"];\n" +
+				"  \"foo/bar\" [label=\"bar\", shape=diamond];\n" +
+				"  \"foo/all\" [label=\"all\", style=dotted];\n" +
+				"  \"baz\" [label=\"baz\", color=gray, fontcolor=gray];\n" +
+				"  \"qux\" [label=\"qux\"];\n" +
+				"  \"foo\" -> \"foo/bar\";\n" +
+				"  \"foo\" -> \"foo/all\";\n" +
+				"  \"foo/bar\" -> \"qux\" [style=dashed];\n" +
+				"}\n",
+		},
+		{
+			name:   "mermaid",
+			format: GraphFormatMermaid,
+			expected: "flowchart TD\n" +
+				"  p0[\"foo\"]\n" +
+				"  p1{\"bar\"}\n" +
+				"  p2[\"all\"]:::runAllSiblings\n" +
+				"  p3[\"baz\"]:::hidden\n" +
+				"  p4[\"qux\"]\n" +
+				"  p0 --> p1\n" +
+				"  p0 --> p2\n" +
+				"  p1 -.-> p4\n" +
+				"  classDef hidden stroke:#999,color:#999\n" +
+				"  classDef runAllSiblings stroke-dasharray: 3 3\n",
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := graphTestRunner().WriteGraph(&out, u.format); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out.String() != u.expected {
+				t.Errorf("\nactual:\n%s\nexpected:\n%s\n", out.String(), u.expected)
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	if err := graphTestRunner().WriteGraph(&out, "png"); err == nil {
+		t.Error("expected error for unknown format, got nil")
+	}
+}
+
+func TestBindToCommandGraph(t *testing.T) {
+	w := graphTestRunner()
+	w.EnableGraphCommand()
+	// the graph does not depend on the RunData, e.g. on a configuration file
+	cmd := &cobra.Command{Use: "init", PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("missing configuration")
+	}}
+	w.BindToCommand(cmd)
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"phase", "graph", "--format", "mermaid"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var expected bytes.Buffer
+	_ = w.WriteGraph(&expected, GraphFormatMermaid)
+	if out.String() != expected.String() {
+		t.Errorf("\nactual:\n%s\nexpected:\n%s\n", out.String(), expected.String())
+	}
+
+	if c, _, _ := cmd.Find([]string{"phase", "graph"}); !c.Hidden {
+		t.Error("expected graph subcommand to be hidden")
+	}
+}
@@ -0,0 +1,134 @@
+package workflow
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// PhaseEventType defines the type of a phase lifecycle event.
+type PhaseEventType string
+
+const (
+	// PhaseStarted is notified before executing the phase action.
+	PhaseStarted PhaseEventType = "started"
+
+	// PhaseSkipped is notified when the phase is not executed; the event Reason
+	// describes why, e.g. filtered, skipped or condition-false.
+	PhaseSkipped PhaseEventType = "skipped"
+
+	// PhaseRetrying is notified when an attempt of the phase action fails and the
+	// phase is going to be retried according to its RetryPolicy.
+	PhaseRetrying PhaseEventType = "retrying"
+
+	// PhaseSucceeded is notified when the phase action completes successfully.
+	PhaseSucceeded PhaseEventType = "succeeded"
+
+	// PhaseFailed is notified when the phase action, or its run condition, fails.
+	PhaseFailed PhaseEventType = "failed"
+)
+
+// PhaseEvent describes a lifecycle event of a phase of the workflow.
+type PhaseEvent struct {
+	// Type of the event.
+	Type PhaseEventType
+
+	// Phase is the full name of the phase, that corresponds to the absolute path
+	// of the phase in the workflow.
+	Phase string
+
+	// Level is the level of nesting of the phase into the workflow.
+	Level int
+
+	// Time is when the event occurred.
+	Time time.Time
+
+	// Duration of the phase action; it is set only for PhaseSucceeded and PhaseFailed events.
+	Duration time.Duration
+
+	// Reason describes why the phase is not executed; it is set only for PhaseSkipped events.
+	Reason SkipReason
+
+	// Message is the human-readable explanation of Reason, e.g. the reason returned by RunIfReason;
+	// it is set only for PhaseSkipped events.
+	Message string
+
+	// Attempt is the number of attempts of the phase action executed so far; it is set only
+	// for PhaseRetrying, PhaseSucceeded and PhaseFailed events.
+	Attempt int
+
+	// Err is the error of the phase; it is set only for PhaseRetrying and PhaseFailed events.
+	Err error
+}
+
+// Observer defines the interface to be implemented for receiving the lifecycle events
+// of the phases executed by a Runner, e.g. for logging, metrics or progress reporting.
+// Notifications are serialized by the Runner, also when phases are executed in parallel.
+type Observer interface {
+	OnPhaseEvent(event PhaseEvent)
+}
+
+// ObserverFunc is an adapter to allow the use of ordinary functions as Observer.
+type ObserverFunc func(event PhaseEvent)
+
+// OnPhaseEvent calls f(event).
+func (f ObserverFunc) OnPhaseEvent(event PhaseEvent) {
+	f(event)
+}
+
+// AddObserver registers an observer that will be notified about the lifecycle events
+// of the phases executed by the Runner.
+func (e *Runner) AddObserver(o Observer) {
+	e.observers = append(e.observers, o)
+}
+
+// notify sends the event for the given phase to all the observers.
+func (x *execution) notify(p *phaseRunner, event PhaseEvent) {
+	if len(x.observers) == 0 {
+		return
+	}
+	event.Phase = p.generatedName
+	event.Level = p.level
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	x.notifyMu.Lock()
+	defer x.notifyMu.Unlock()
+	for _, o := range x.observers {
+		o.OnPhaseEvent(event)
+	}
+}
+
+// phaseStarted notifies the PhaseStarted event and returns the start time of the phase.
+func (x *execution) phaseStarted(p *phaseRunner) time.Time {
+	start := time.Now()
+	x.notify(p, PhaseEvent{Type: PhaseStarted, Time: start})
+	return start
+}
+
+// phaseSkipped logs why the phase is skipped and notifies the PhaseSkipped event.
+// If message is empty, the description of the reason is used.
+func (x *execution) phaseSkipped(p *phaseRunner, reason SkipReason, message string) {
+	if message == "" {
+		message = reason.Description()
+	}
+	klog.V(x.skipLogVerbosity).Infof("skipping phase %s (%s): %s", p.generatedName, reason, message)
+	x.notify(p, PhaseEvent{Type: PhaseSkipped, Reason: reason, Message: message})
+}
+
+// phaseRetrying notifies the PhaseRetrying event.
+func (x *execution) phaseRetrying(p *phaseRunner, attempt int, err error) {
+	x.notify(p, PhaseEvent{Type: PhaseRetrying, Attempt: attempt, Err: err})
+}
+
+// phaseSucceeded notifies the PhaseSucceeded event.
+func (x *execution) phaseSucceeded(p *phaseRunner, start time.Time, attempts int) {
+	x.notify(p, PhaseEvent{Type: PhaseSucceeded, Duration: time.Since(start), Attempt: attempts})
+}
+
+// phaseFailed notifies the PhaseFailed event and returns the given error.
+func (x *execution) phaseFailed(p *phaseRunner, start time.Time, attempts int, err error) error {
+	x.notify(p, PhaseEvent{Type: PhaseFailed, Duration: time.Since(start), Attempt: attempts, Err: err})
+	return err
+}
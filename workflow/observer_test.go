@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+type eventRecorder struct {
+	events []string
+}
+
+func (r *eventRecorder) OnPhaseEvent(event PhaseEvent) {
+	entry := string(event.Type) + " " + event.Phase
+	if event.Reason != "" {
+		entry += " (" + string(event.Reason) + ")"
+	}
+	if event.Err != nil {
+		entry += ": " + event.Err.Error()
+	}
+	r.events = append(r.events, entry)
+}
+
+func TestObserver(t *testing.T) {
+	var usecases = []struct {
+		name           string
+		options        RunnerOptions
+		expectedEvents []string
+	}{
+		{
+			name:    "events are notified in execution order",
+			options: RunnerOptions{SkipPhases: []string{"qux"}},
+			expectedEvents: []string{
+				"started foo",
+				"succeeded foo",
+				"started foo/bar",
+				"succeeded foo/bar",
+				"skipped foo/baz (condition-false)",
+				"skipped qux (skipped)",
+				"started quux",
+				"failed quux: error execution phase quux: run fails",
+			},
+		},
+		{
+			name:    "filtered phases are notified as skipped",
+			options: RunnerOptions{FilterPhases: []string{"foo/bar"}},
+			expectedEvents: []string{
+				"skipped foo (filtered)",
+				"started foo/bar",
+				"succeeded foo/bar",
+				"skipped foo/baz (filtered)",
+				"skipped qux (filtered)",
+				"skipped quux (filtered)",
+			},
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			recorder := &eventRecorder{}
+			var w = Runner{
+				Phases: []Phase{
+					phaseBuilder1("foo", nil,
+						phaseBuilder1("bar", runConditionTrue),
+						phaseBuilder1("baz", runConditionFalse),
+					),
+					phaseBuilder1("qux", nil),
+					phaseBuilder2("quux", nil, runFails),
+				},
+				Options: u.options,
+			}
+			w.AddObserver(recorder)
+
+			_ = w.Run([]string{})
+			if !reflect.DeepEqual(recorder.events, u.expectedEvents) {
+				t.Errorf("\nevents:\n\t%v\nexpected:\n\t%v\n", recorder.events, u.expectedEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"sort"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// phaseResult is the outcome of a phase executed by runParallel.
+type phaseResult struct {
+	phase *phaseRunner
+	err   error
+}
+
+// runParallel executes the phases that should be run as a DAG, where each phase
+// depends on its parent phase and on the phases listed in its Dependencies.
+// Phases whose dependencies are completed are started in the execution order, up to
+// RunnerOptions.MaxParallelism at a time.
+// When a phase fails no further phases are started, the phases already running are awaited
+// and all the errors are aggregated.
+func (e *Runner) runParallel(phaseRunFlags map[string]bool, phaseDependencies map[*phaseRunner][]*phaseRunner, fn func(*phaseRunner) error) error {
+	// builds the DAG of the phases to be run
+	var phases []*phaseRunner
+	order := map[*phaseRunner]int{}
+	pending := map[*phaseRunner]int{}
+	dependents := map[*phaseRunner][]*phaseRunner{}
+	e.visitAll(func(p *phaseRunner) error {
+		if run, ok := phaseRunFlags[p.generatedName]; !run || !ok {
+			return nil
+		}
+		order[p] = len(phases)
+		phases = append(phases, p)
+
+		deps := map[*phaseRunner]struct{}{}
+		if p.parent != nil && phaseRunFlags[p.parent.generatedName] {
+			deps[p.parent] = struct{}{}
+		}
+		for _, d := range phaseDependencies[p] {
+			deps[d] = struct{}{}
+		}
+		pending[p] = len(deps)
+		for d := range deps {
+			dependents[d] = append(dependents[d], p)
+		}
+		return nil
+	})
+
+	limit := e.Options.MaxParallelism
+	if limit <= 0 {
+		limit = len(phases)
+	}
+
+	// ready keeps the phases that can be started, in execution order
+	var ready []*phaseRunner
+	for _, p := range phases {
+		if pending[p] == 0 {
+			ready = append(ready, p)
+		}
+	}
+
+	var failed []phaseResult
+	running := 0
+	results := make(chan phaseResult)
+	for {
+		for len(failed) == 0 && len(ready) > 0 && running < limit {
+			p := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				results <- phaseResult{phase: p, err: fn(p)}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+
+		r := <-results
+		running--
+		if r.err != nil {
+			failed = append(failed, r)
+			continue
+		}
+		for _, d := range dependents[r.phase] {
+			pending[d]--
+			if pending[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+		sort.SliceStable(ready, func(i, j int) bool {
+			return order[ready[i]] < order[ready[j]]
+		})
+	}
+
+	// reports errors in execution order
+	sort.Slice(failed, func(i, j int) bool {
+		return order[failed[i].phase] < order[failed[j].phase]
+	})
+	if len(failed) == 1 {
+		return failed[0].err
+	}
+	errs := make([]error, 0, len(failed))
+	for _, r := range failed {
+		errs = append(errs, r.err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
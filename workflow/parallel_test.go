@@ -0,0 +1,124 @@
+package workflow
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestRunParallel(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func(data RunData) error {
+		return func(data RunData) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	// foo and bar can only complete if they are executed concurrently
+	started := make(chan struct{}, 2)
+	barrier := func(name string) func(data RunData) error {
+		return func(data RunData) error {
+			started <- struct{}{}
+			select {
+			case <-time.After(time.Second):
+				return errors.Errorf("%s: phases are not running concurrently", name)
+			case <-waitFor(started, 2):
+			}
+			return record(name)(data)
+		}
+	}
+
+	var w = Runner{
+		Phases: []Phase{
+			{Name: "foo", Run: barrier("foo"),
+				Phases: []Phase{{Name: "child", Run: record("foo/child")}},
+			},
+			{Name: "bar", Run: barrier("bar")},
+			{Name: "baz", Run: record("baz"), Dependencies: []string{"foo/child", "bar"}},
+		},
+		Options: RunnerOptions{Parallel: true},
+	}
+
+	if err := w.Run([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	index := map[string]int{}
+	for i, n := range order {
+		index[n] = i
+	}
+	if len(index) != 4 {
+		t.Fatalf("expected all the phases to run, got %v", order)
+	}
+	if index["foo/child"] < index["foo"] {
+		t.Errorf("nested phase executed before its parent: %v", order)
+	}
+	if index["baz"] < index["foo/child"] || index["baz"] < index["bar"] {
+		t.Errorf("phase executed before its dependencies: %v", order)
+	}
+}
+
+// waitFor returns a channel that is closed once all the n phases have started.
+func waitFor(started chan struct{}, n int) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for len(started) < n {
+			time.Sleep(time.Millisecond)
+		}
+		close(done)
+	}()
+	return done
+}
+
+func TestRunParallelMaxParallelism(t *testing.T) {
+	// with a single worker, phases are executed in the same order of the sequential mode
+	callstack = []string{}
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder1("foo", nil, phaseBuilder1("bar", nil)),
+			phaseBuilder1("baz", nil),
+			phaseBuilder1("qux", nil),
+		},
+		Options: RunnerOptions{Parallel: true, MaxParallelism: 1},
+	}
+
+	if err := w.Run([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"foo", "bar", "baz", "qux"}
+	if !reflect.DeepEqual(callstack, expected) {
+		t.Errorf("\ncallstack:\n\t%v\nexpected:\n\t%v\n", callstack, expected)
+	}
+}
+
+func TestRunParallelHandleErrors(t *testing.T) {
+	callstack = []string{}
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder2("foo", nil, runFails),
+			phaseBuilder2("bar", nil, runFails),
+			phaseBuilder1("baz", nil),
+			{Name: "qux", Run: runBuilder("qux"), Dependencies: []string{"foo"}},
+		},
+		Options: RunnerOptions{Parallel: true, MaxParallelism: 3},
+	}
+
+	err := w.Run([]string{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	expected := "[error execution phase foo: run fails, error execution phase bar: run fails]"
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+	if !reflect.DeepEqual(callstack, []string{"baz"}) {
+		t.Errorf("expected only baz to be executed, got %v", callstack)
+	}
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package workflow
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -56,13 +60,44 @@ type Phase struct {
 	// Run defines a function implementing the phase action.
 	// It is recommended to implent type assertion, e.g. using golang type switch,
 	// for validating the RunData type.
+	// Nb. Run can not be interrupted: when the workflow is interrupted or the phase Timeout
+	// expires, the runner waits for a bounded grace period and then leaves Run executing in
+	// background; use RunContext for actions that must stop.
 	Run func(data RunData) error
 
+	// RunContext defines a context-aware function implementing the phase action.
+	// The context is canceled when the workflow is interrupted or when the phase
+	// Timeout expires; if both RunContext and Run are set, RunContext takes precedence.
+	RunContext func(ctx context.Context, data RunData) error
+
+	// Timeout defines the maximum duration of the phase action (if zero, no timeout).
+	// Nb. the timeout does not include the execution of nested phases, and it is applied to each attempt;
+	// a phase implementing only Run is not stopped when the timeout expires (see Run).
+	Timeout time.Duration
+
+	// Retry defines how the phase action is retried when it fails (if nil, the phase is not retried).
+	Retry *RetryPolicy
+
+	// Rollback defines a function that undoes the phase action.
+	// When a phase of the workflow fails, the Rollback functions of the phases already
+	// executed are invoked in reverse order.
+	Rollback func(data RunData) error
+
+	// DryRun defines a function that describes the phase action without executing it;
+	// it is invoked instead of Run when the runner is in dry-run mode.
+	DryRun func(data RunData) error
+
 	// RunIf define a function that implements a condition that should be checked
 	// before executing the phase action.
 	// If this function return nil, the phase action is always executed.
 	RunIf func(data RunData) (bool, error)
 
+	// RunIfReason defines a condition like RunIf, that additionally returns a human-readable
+	// reason explaining why the phase action is not executed; the reason is logged and
+	// recorded in the report of the workflow. If both RunIfReason and RunIf are set,
+	// RunIfReason takes precedence.
+	RunIfReason func(data RunData) (ok bool, reason string, err error)
+
 	// InheritFlags defines the list of flags that the cobra command generated for this phase should Inherit
 	// from local flags defined in the parent command / or additional flags defined in the phase runner.
 	// If the values is not set or empty, no flags will be assigned to the command
@@ -79,7 +114,14 @@ type Phase struct {
 	// If not set a phase will adopt the args of the top level command.
 	ArgsValidator cobra.PositionalArgs
 
+	// Tags are labels for selecting phases across different subtrees of the workflow, e.g. "network"
+	// or "slow"; see RunnerOptions.FilterTags and RunnerOptions.SkipTags.
+	// Nb. selecting a phase by tag selects its nested phases as well.
+	Tags []string
+
 	// Dependencies is a list of phases that the specific phase depends on.
+	// Each dependency is the path of a phase relative to the parent of this phase (e.g. "ca" for a sibling phase),
+	// or the full path of a phase in the workflow (e.g. "certs/ca"); use a leading "/" to force a full path.
 	Dependencies []string
 }
 
@@ -87,3 +129,31 @@ type Phase struct {
 func (t *Phase) AppendPhase(phase Phase) {
 	t.Phases = append(t.Phases, phase)
 }
+
+// hasTag returns true if the phase has the given tag.
+func (t *Phase) hasTag(tag string) bool {
+	for _, x := range t.Tags {
+		if strings.EqualFold(x, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// conditional returns true if the phase action depends on a run condition.
+func (t *Phase) conditional() bool {
+	return t.RunIf != nil || t.RunIfReason != nil
+}
+
+// checkCondition evaluates the run condition of the phase, returning the reason why the
+// phase action should not be executed, if any.
+func (t *Phase) checkCondition(data RunData) (bool, string, error) {
+	if t.RunIfReason != nil {
+		return t.RunIfReason(data)
+	}
+	if t.RunIf != nil {
+		ok, err := t.RunIf(data)
+		return ok, "", err
+	}
+	return true, "", nil
+}
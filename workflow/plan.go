@@ -0,0 +1,244 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SkipReason describes why a phase is not executed.
+type SkipReason string
+
+const (
+	// SkipReasonFiltered is used for phases not included in RunnerOptions.FilterPhases.
+	SkipReasonFiltered SkipReason = "filtered"
+
+	// SkipReasonSkipped is used for phases included in RunnerOptions.SkipPhases.
+	SkipReasonSkipped SkipReason = "skipped"
+
+	// SkipReasonRunAllSiblings is used for phases marked as RunAllSiblings, that don't
+	// have an action and are meant only to run all the sibling phases from the phase subcommand.
+	SkipReasonRunAllSiblings SkipReason = "run-all-siblings"
+
+	// SkipReasonUnresolvedDependency is used for phases whose Dependencies are not executed before them.
+	SkipReasonUnresolvedDependency SkipReason = "unresolved-dependency"
+
+	// SkipReasonConditionFalse is used for phases whose RunIf condition is not satisfied.
+	SkipReasonConditionFalse SkipReason = "condition-false"
+
+	// SkipReasonCompleted is used for phases completed by a previous execution, when resuming
+	// from a checkpoint.
+	SkipReasonCompleted SkipReason = "completed"
+
+	// SkipReasonUser is used for phases skipped by RunnerOptions.BeforePhase, e.g. by the user
+	// when asked for a confirmation.
+	SkipReasonUser SkipReason = "user"
+)
+
+// Description returns the human-readable explanation of the reason.
+func (r SkipReason) Description() string {
+	switch r {
+	case SkipReasonFiltered:
+		return "not selected by the phase filter"
+	case SkipReasonSkipped:
+		return "selected to be skipped"
+	case SkipReasonRunAllSiblings:
+		return "runs all its sibling phases"
+	case SkipReasonUnresolvedDependency:
+		return "dependencies not executed"
+	case SkipReasonConditionFalse:
+		return "run condition not satisfied"
+	case SkipReasonCompleted:
+		return "already completed by a previous execution"
+	case SkipReasonUser:
+		return "skipped by the user"
+	}
+	return string(r)
+}
+
+// PlanEntry describes a phase in the execution plan of the workflow.
+type PlanEntry struct {
+	// Name is the full name of the phase, that corresponds to the absolute path
+	// of the phase in the workflow.
+	Name string `json:"name"`
+
+	// Level is the level of nesting of the phase into the workflow.
+	Level int `json:"level"`
+
+	// Run defines if the phase will be executed.
+	Run bool `json:"run"`
+
+	// Reason describes why the phase will not be executed.
+	Reason SkipReason `json:"reason,omitempty"`
+
+	// Message is the human-readable explanation of Reason.
+	Message string `json:"message,omitempty"`
+
+	// IncludedAsDependency defines if the phase is included only because a filtered phase depends on it.
+	IncludedAsDependency bool `json:"includedAsDependency,omitempty"`
+
+	// MissingDependencies is the list of the unresolved dependencies of the phase.
+	MissingDependencies []string `json:"missingDependencies,omitempty"`
+
+	// Conditional defines if the phase action depends on a RunIf condition,
+	// that is evaluated only at execution time.
+	Conditional bool `json:"conditional,omitempty"`
+
+	// Hidden defines if the phase is hidden in the workflow help.
+	Hidden bool `json:"hidden,omitempty"`
+
+	// RunAllSiblings defines if the phase is meant to run all its sibling phases.
+	RunAllSiblings bool `json:"runAllSiblings,omitempty"`
+}
+
+// ExecutionPlan is the list of the phases of the workflow, in execution order.
+type ExecutionPlan struct {
+	Entries []PlanEntry `json:"entries"`
+}
+
+// Lookup returns the entry for the phase with the given full name, if any.
+func (p *ExecutionPlan) Lookup(name string) (PlanEntry, bool) {
+	for _, entry := range p.Entries {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+	return PlanEntry{}, false
+}
+
+// String returns the human-readable representation of the execution plan.
+func (p *ExecutionPlan) String() string {
+	var b strings.Builder
+	b.WriteString("The following phases will be executed:\n")
+	for _, entry := range p.Entries {
+		status := "[run] "
+		if !entry.Run {
+			status = "[skip]"
+		}
+		use := entry.Name
+		if pos := strings.LastIndex(use, phaseSeparator); pos != -1 {
+			use = use[pos:]
+		}
+		b.WriteString(status)
+		b.WriteString(" ")
+		b.WriteString(strings.Repeat("  ", entry.Level))
+		b.WriteString(use)
+		switch {
+		case entry.Reason != "":
+			fmt.Fprintf(&b, " (%s: %s)", entry.Reason, entry.Message)
+		case entry.IncludedAsDependency && entry.Conditional:
+			b.WriteString(" (dependency, conditional)")
+		case entry.IncludedAsDependency:
+			b.WriteString(" (dependency)")
+		case entry.Conditional:
+			b.WriteString(" (conditional)")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Plan returns the execution plan of the workflow according to the given options, without
+// executing any phase.
+// Differently from Run, phases with unresolved dependencies are reported in the plan instead
+// of returning an error.
+func (e *Runner) Plan(options RunnerOptions) (*ExecutionPlan, error) {
+	e.prepareForExecution()
+
+	checkpoint, err := newCheckpointRecorder(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.computeExecutionPlan(options, checkpoint)
+}
+
+// LastPlan returns the execution plan computed by the last dry run, if any.
+func (e *Runner) LastPlan() *ExecutionPlan {
+	return e.lastPlan
+}
+
+// computeExecutionPlan returns the execution plan according to the given options.
+func (e *Runner) computeExecutionPlan(options RunnerOptions, checkpoint *checkpointRecorder) (*ExecutionPlan, error) {
+	skipReasons, included, err := e.computeSkipReasons(options)
+	if err != nil {
+		return nil, err
+	}
+
+	_, missedDeps := e.computeDependencies(runFlags(skipReasons))
+
+	plan := &ExecutionPlan{Entries: []PlanEntry{}}
+	e.visitAll(func(p *phaseRunner) error {
+		reason := skipReasons[p.generatedName]
+		switch {
+		case reason != "":
+		case len(missedDeps[p]) > 0:
+			reason = SkipReasonUnresolvedDependency
+		case checkpoint.isCompleted(p):
+			reason = SkipReasonCompleted
+		case p.RunAllSiblings:
+			reason = SkipReasonRunAllSiblings
+		}
+
+		message := reason.Description()
+		if reason == SkipReasonUnresolvedDependency {
+			message = fmt.Sprintf("missing %s", strings.Join(missedDeps[p], ", "))
+		}
+		if reason == "" {
+			message = ""
+		}
+
+		plan.Entries = append(plan.Entries, PlanEntry{
+			Name:                 p.generatedName,
+			Level:                p.level,
+			Run:                  reason == "",
+			Reason:               reason,
+			Message:              message,
+			IncludedAsDependency: included[p.generatedName],
+			MissingDependencies:  missedDeps[p],
+			Conditional:          p.conditional(),
+			Hidden:               p.Hidden,
+			RunAllSiblings:       p.RunAllSiblings,
+		})
+		return nil
+	})
+	return plan, nil
+}
+
+// dryRun prints the execution plan and invokes the DryRun functions of the phases to be run.
+func (e *Runner) dryRun(x *execution) error {
+	plan, err := e.computeExecutionPlan(e.Options, x.checkpoint)
+	if err != nil {
+		return err
+	}
+	e.lastPlan = plan
+
+	if _, err := fmt.Fprint(e.OutOrStdout(), plan.String()); err != nil {
+		return errors.Wrap(err, "failed to print the execution plan")
+	}
+
+	for _, entry := range plan.Entries {
+		if !entry.Run {
+			continue
+		}
+		p := e.lookupPhaseRunner(entry.Name)
+		if p.DryRun == nil {
+			continue
+		}
+		if err := p.DryRun(x.data); err != nil {
+			return errors.Wrapf(err, "error dry-run phase %s", p.generatedName)
+		}
+	}
+	return nil
+}
+
+// lookupPhaseRunner returns the phaseRunner with the given full name, if any.
+func (e *Runner) lookupPhaseRunner(name string) *phaseRunner {
+	for _, p := range e.phaseRunners {
+		if p.generatedName == name {
+			return p
+		}
+	}
+	return nil
+}
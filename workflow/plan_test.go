@@ -0,0 +1,121 @@
+package workflow
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	callstack = []string{}
+	var out bytes.Buffer
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder1("foo", nil,
+				phaseBuilder1("bar", runConditionTrue),
+				phaseBuilder1("baz", nil),
+			),
+			{Name: "qux", Run: runBuilder("qux"), DryRun: runBuilder("dry-run qux")},
+		},
+		Options: RunnerOptions{SkipPhases: []string{"foo/baz"}, DryRun: true},
+	}
+	w.SetOutput(&out)
+
+	if err := w.Run([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(callstack, []string{"dry-run qux"}) {
+		t.Errorf("expected only DryRun functions to be invoked, got %v", callstack)
+	}
+
+	expectedPlan := &ExecutionPlan{Entries: []PlanEntry{
+		{Name: "foo", Level: 0, Run: true},
+		{Name: "foo/bar", Level: 1, Run: true, Conditional: true},
+		{Name: "foo/baz", Level: 1, Run: false, Reason: SkipReasonSkipped, Message: "selected to be skipped"},
+		{Name: "qux", Level: 0, Run: true},
+	}}
+	if !reflect.DeepEqual(w.LastPlan(), expectedPlan) {
+		t.Errorf("\nplan:\n\t%v\nexpected:\n\t%v\n", w.LastPlan(), expectedPlan)
+	}
+
+	expectedOut := "The following phases will be executed:\n" +
+		"[run]  foo\n" +
+		"[run]    /bar (conditional)\n" +
+		"[skip]   /baz (skipped: selected to be skipped)\n" +
+		"[run]  qux\n"
+	if out.String() != expectedOut {
+		t.Errorf("\nactual:\n%s\nexpected:\n%s\n", out.String(), expectedOut)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder("foo",
+				phaseBuilder("bar"),
+				Phase{Name: "all", RunAllSiblings: true},
+				phaseBuilder3("baz", true),
+			),
+			phaseBuilder("qux"),
+			{Name: "quux", Dependencies: []string{"qux"}},
+		},
+	}
+
+	var usecases = []struct {
+		name     string
+		options  RunnerOptions
+		expected []PlanEntry
+	}{
+		{
+			name: "no options > all phases",
+			expected: []PlanEntry{
+				{Name: "foo", Level: 0, Run: true},
+				{Name: "foo/bar", Level: 1, Run: true},
+				{Name: "foo/all", Level: 1, Run: false, Reason: SkipReasonRunAllSiblings, Message: "runs all its sibling phases", RunAllSiblings: true},
+				{Name: "foo/baz", Level: 1, Run: true, Hidden: true},
+				{Name: "qux", Level: 0, Run: true},
+				{Name: "quux", Level: 0, Run: true},
+			},
+		},
+		{
+			name:    "reasons are reported",
+			options: RunnerOptions{FilterPhases: []string{"foo", "quux"}, SkipPhases: []string{"foo/bar"}},
+			expected: []PlanEntry{
+				{Name: "foo", Level: 0, Run: true},
+				{Name: "foo/bar", Level: 1, Run: false, Reason: SkipReasonSkipped, Message: "selected to be skipped"},
+				{Name: "foo/all", Level: 1, Run: false, Reason: SkipReasonRunAllSiblings, Message: "runs all its sibling phases", RunAllSiblings: true},
+				{Name: "foo/baz", Level: 1, Run: true, Hidden: true},
+				{Name: "qux", Level: 0, Run: false, Reason: SkipReasonFiltered, Message: "not selected by the phase filter"},
+				{Name: "quux", Level: 0, Run: false, Reason: SkipReasonUnresolvedDependency, Message: "missing qux", MissingDependencies: []string{"qux"}},
+			},
+		},
+		{
+			name:    "dependencies are included",
+			options: RunnerOptions{FilterPhases: []string{"quux"}, IncludeDependencies: true},
+			expected: []PlanEntry{
+				{Name: "foo", Level: 0, Run: false, Reason: SkipReasonFiltered, Message: "not selected by the phase filter"},
+				{Name: "foo/bar", Level: 1, Run: false, Reason: SkipReasonFiltered, Message: "not selected by the phase filter"},
+				{Name: "foo/all", Level: 1, Run: false, Reason: SkipReasonFiltered, Message: "not selected by the phase filter", RunAllSiblings: true},
+				{Name: "foo/baz", Level: 1, Run: false, Reason: SkipReasonFiltered, Message: "not selected by the phase filter", Hidden: true},
+				{Name: "qux", Level: 0, Run: true, IncludedAsDependency: true},
+				{Name: "quux", Level: 0, Run: true},
+			},
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			plan, err := w.Plan(u.options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(plan.Entries, u.expected) {
+				t.Errorf("\nplan:\n\t%v\nexpected:\n\t%v\n", plan.Entries, u.expected)
+			}
+		})
+	}
+
+	if _, err := w.Plan(RunnerOptions{SkipPhases: []string{"invalid"}}); err == nil {
+		t.Error("expected error for invalid phase name, got nil")
+	}
+}
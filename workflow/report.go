@@ -0,0 +1,183 @@
+package workflow
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PhaseStatus defines the status of a phase at the end of the execution of the workflow.
+type PhaseStatus string
+
+const (
+	// PhaseStatusRan is used for phases executed successfully.
+	PhaseStatusRan PhaseStatus = "ran"
+
+	// PhaseStatusSkipped is used for phases excluded by RunnerOptions or completed by a previous execution.
+	PhaseStatusSkipped PhaseStatus = "skipped"
+
+	// PhaseStatusConditionFalse is used for phases whose RunIf condition is not satisfied.
+	PhaseStatusConditionFalse PhaseStatus = "condition-false"
+
+	// PhaseStatusFailed is used for phases whose action, or run condition, failed.
+	PhaseStatusFailed PhaseStatus = "failed"
+
+	// PhaseStatusNotRun is used for phases not reached because the workflow stopped earlier.
+	PhaseStatusNotRun PhaseStatus = "not-run"
+)
+
+// PhaseReport describes the execution of a phase of the workflow.
+type PhaseReport struct {
+	// Name is the full name of the phase.
+	Name string `json:"name" yaml:"name"`
+
+	// Level is the level of nesting of the phase into the workflow.
+	Level int `json:"level" yaml:"level"`
+
+	// Status of the phase at the end of the execution.
+	Status PhaseStatus `json:"status" yaml:"status"`
+
+	// Reason describes why the phase was not executed, if any.
+	Reason SkipReason `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	// Message is the human-readable explanation of Reason.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+
+	// IncludedAsDependency defines if the phase was executed only because a filtered phase depends on it.
+	IncludedAsDependency bool `json:"includedAsDependency,omitempty" yaml:"includedAsDependency,omitempty"`
+
+	// StartTime is when the phase action started, if executed.
+	StartTime *time.Time `json:"startTime,omitempty" yaml:"startTime,omitempty"`
+
+	// Duration of the phase action, including all the attempts.
+	Duration string `json:"duration,omitempty" yaml:"duration,omitempty"`
+
+	// Attempts is the number of executions of the phase action.
+	Attempts int `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+
+	// AttemptErrors are the errors of the failed attempts that were retried.
+	AttemptErrors []string `json:"attemptErrors,omitempty" yaml:"attemptErrors,omitempty"`
+
+	// Error is the error of the phase, if failed.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// RolledBack defines if the Rollback function of the phase was invoked.
+	RolledBack bool `json:"rolledBack,omitempty" yaml:"rolledBack,omitempty"`
+}
+
+// Report describes the execution of the workflow.
+type Report struct {
+	// StartTime is when the execution of the workflow started.
+	StartTime time.Time `json:"startTime" yaml:"startTime"`
+
+	// Duration of the execution of the workflow.
+	Duration string `json:"duration" yaml:"duration"`
+
+	// Succeeded defines if the workflow completed without errors.
+	Succeeded bool `json:"succeeded" yaml:"succeeded"`
+
+	// Error is the error returned by the workflow, if any.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// Phases is the list of the phases of the workflow, in execution order.
+	Phases []PhaseReport `json:"phases" yaml:"phases"`
+
+	// Store is the content of the store of the workflow at the end of the execution,
+	// if RunnerOptions.ReportStore is set.
+	Store map[string]interface{} `json:"store,omitempty" yaml:"store,omitempty"`
+}
+
+// Report returns the report of the last execution of the workflow, if any.
+func (e *Runner) Report() *Report {
+	return e.lastReport
+}
+
+// reportRecorder is an Observer building the report of an execution of the workflow.
+type reportRecorder struct {
+	mu     sync.Mutex
+	report *Report
+	index  map[string]int
+}
+
+// newReportRecorder returns a reportRecorder with an entry for each of the given phases;
+// included is the set of the phases included because of a dependency.
+func newReportRecorder(phaseRunners []*phaseRunner, included map[string]bool) *reportRecorder {
+	r := &reportRecorder{
+		report: &Report{StartTime: time.Now(), Phases: []PhaseReport{}},
+		index:  map[string]int{},
+	}
+	for _, p := range phaseRunners {
+		r.index[p.generatedName] = len(r.report.Phases)
+		r.report.Phases = append(r.report.Phases, PhaseReport{
+			Name:   p.generatedName,
+			Level:  p.level,
+			Status: PhaseStatusNotRun,
+
+			IncludedAsDependency: included[p.generatedName],
+		})
+	}
+	return r
+}
+
+// OnPhaseEvent updates the report entry of the phase.
+func (r *reportRecorder) OnPhaseEvent(event PhaseEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.index[event.Phase]
+	if !ok {
+		return
+	}
+	entry := &r.report.Phases[i]
+	switch event.Type {
+	case PhaseStarted:
+		start := event.Time
+		entry.StartTime = &start
+	case PhaseSkipped:
+		entry.Status = PhaseStatusSkipped
+		if event.Reason == SkipReasonConditionFalse {
+			entry.Status = PhaseStatusConditionFalse
+		}
+		entry.Reason = event.Reason
+		entry.Message = event.Message
+	case PhaseRetrying:
+		entry.AttemptErrors = append(entry.AttemptErrors, event.Err.Error())
+	case PhaseSucceeded:
+		entry.Status = PhaseStatusRan
+		entry.Duration = event.Duration.String()
+		entry.Attempts = event.Attempt
+	case PhaseFailed:
+		entry.Status = PhaseStatusFailed
+		entry.Duration = event.Duration.String()
+		entry.Attempts = event.Attempt
+		entry.Error = event.Err.Error()
+	}
+}
+
+// finalize completes the report with the outcome of the workflow and the content of the store, if not nil.
+func (r *reportRecorder) finalize(err error, s *Store) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.report.Duration = time.Since(r.report.StartTime).String()
+	r.report.Succeeded = err == nil
+	if s != nil {
+		if snapshot := s.Snapshot(); len(snapshot) > 0 {
+			r.report.Store = snapshot
+		}
+	}
+	if err != nil {
+		r.report.Error = err.Error()
+	}
+
+	var rollbackErr *RollbackError
+	if errors.As(err, &rollbackErr) {
+		for _, name := range rollbackErr.RolledBack {
+			if i, ok := r.index[name]; ok {
+				r.report.Phases[i].RolledBack = true
+			}
+		}
+	}
+	return r.report
+}
@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestReport(t *testing.T) {
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder1("foo", nil,
+				phaseBuilder1("bar", runConditionFalse),
+			),
+			phaseBuilder1("baz", nil),
+			{Name: "garply", Run: runPass, RunIfReason: func(data RunData) (bool, string, error) {
+				return false, "already configured", nil
+			}},
+			{Name: "qux", Run: flakyRun(1, errors.New("transient failure")), Retry: &RetryPolicy{MaxAttempts: 2, Delay: time.Millisecond}},
+			{Name: "quux", Run: runFails, Rollback: runPass},
+			phaseBuilder1("corge", nil),
+		},
+		Options: RunnerOptions{SkipPhases: []string{"baz"}},
+	}
+
+	if w.Report() != nil {
+		t.Fatal("expected no report before Run")
+	}
+	if err := w.Run([]string{}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	report := w.Report()
+	if report == nil {
+		t.Fatal("expected report, got nil")
+	}
+	if report.Succeeded || report.Error == "" {
+		t.Errorf("expected report of a failed workflow, got succeeded=%v error=%q", report.Succeeded, report.Error)
+	}
+
+	type summary struct {
+		name          string
+		status        PhaseStatus
+		reason        SkipReason
+		message       string
+		attempts      int
+		attemptErrors int
+		failed        bool
+		started       bool
+	}
+	var actual []summary
+	for _, p := range report.Phases {
+		actual = append(actual, summary{
+			name:          p.Name,
+			status:        p.Status,
+			reason:        p.Reason,
+			message:       p.Message,
+			attempts:      p.Attempts,
+			attemptErrors: len(p.AttemptErrors),
+			failed:        p.Error != "",
+			started:       p.StartTime != nil,
+		})
+	}
+	expected := []summary{
+		{name: "foo", status: PhaseStatusRan, attempts: 1, started: true},
+		{name: "foo/bar", status: PhaseStatusConditionFalse, reason: SkipReasonConditionFalse, message: "run condition not satisfied"},
+		{name: "baz", status: PhaseStatusSkipped, reason: SkipReasonSkipped, message: "selected to be skipped"},
+		{name: "garply", status: PhaseStatusConditionFalse, reason: SkipReasonConditionFalse, message: "already configured"},
+		{name: "qux", status: PhaseStatusRan, attempts: 2, attemptErrors: 1, started: true},
+		{name: "quux", status: PhaseStatusFailed, attempts: 1, failed: true, started: true},
+		{name: "corge", status: PhaseStatusNotRun},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("\nreport:\n\t%v\nexpected:\n\t%v\n", actual, expected)
+	}
+}
@@ -0,0 +1,95 @@
+package workflow
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// BackoffStrategy defines how the delay between the attempts of a phase grows.
+type BackoffStrategy string
+
+const (
+	// BackoffFixed waits the same delay before each attempt.
+	BackoffFixed BackoffStrategy = "fixed"
+
+	// BackoffExponential multiplies the delay by RetryPolicy.Multiplier after each attempt.
+	BackoffExponential BackoffStrategy = "exponential"
+)
+
+// RetryPolicy defines how the action of a phase is retried when it fails.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of executions of the phase action, including the first one.
+	// If less than 2, the phase action is not retried.
+	MaxAttempts int
+
+	// Backoff is the strategy used for computing the delay between attempts (if empty, BackoffFixed).
+	Backoff BackoffStrategy
+
+	// Delay is the delay before the first retry.
+	Delay time.Duration
+
+	// MaxDelay caps the delay computed by BackoffExponential (if zero, no cap).
+	MaxDelay time.Duration
+
+	// Multiplier is the factor applied to the delay after each attempt by BackoffExponential (if zero, 2).
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to the given fraction, e.g. 0.2 means +/-20% (if zero, no jitter).
+	Jitter float64
+
+	// Retryable decides whether the error returned by the phase action should be retried
+	// (if nil, all the errors are retried).
+	// Nb. phases are never retried when the workflow context is canceled.
+	Retryable func(err error) bool
+}
+
+// delay returns the delay to wait before the given attempt (starting from 2).
+func (r *RetryPolicy) delay(attempt int) time.Duration {
+	d := float64(r.Delay)
+	if r.Backoff == BackoffExponential {
+		multiplier := r.Multiplier
+		if multiplier == 0 {
+			multiplier = 2
+		}
+		d *= math.Pow(multiplier, float64(attempt-2))
+		if r.MaxDelay > 0 && d > float64(r.MaxDelay) {
+			d = float64(r.MaxDelay)
+		}
+	}
+	if r.Jitter > 0 {
+		d *= 1 + r.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(d)
+}
+
+// shouldRetry returns true if the error returned by the given attempt should be retried.
+func (r *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if r == nil || attempt >= r.MaxAttempts {
+		return false
+	}
+	return r.Retryable == nil || r.Retryable(err)
+}
+
+// runPhaseWithRetry executes the action of the given phase according to the phase RetryPolicy,
+// and returns the number of attempts.
+func (e *Runner) runPhaseWithRetry(x *execution, p *phaseRunner) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := e.runPhase(x.ctx, p, x.data)
+		if err == nil || x.ctx.Err() != nil || !p.Retry.shouldRetry(attempt, err) {
+			return attempt, err
+		}
+
+		delay := p.Retry.delay(attempt + 1)
+		klog.Warningf("phase %s failed (attempt %d/%d), retrying in %s: %v", p.generatedName, attempt, p.Retry.MaxAttempts, delay, err)
+		x.phaseRetrying(p, attempt, err)
+
+		select {
+		case <-x.ctx.Done():
+			return attempt, &PhaseCanceledError{Phase: p.generatedName, Err: x.ctx.Err()}
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var errPermanent = errors.New("permanent failure")
+
+func flakyRun(failures int, err error) func(data RunData) error {
+	attempts := 0
+	return func(data RunData) error {
+		attempts++
+		if attempts <= failures {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestRunRetry(t *testing.T) {
+	notPermanent := func(err error) bool {
+		return !errors.Is(err, errPermanent)
+	}
+
+	var usecases = []struct {
+		name             string
+		run              func(data RunData) error
+		retry            *RetryPolicy
+		expectedError    bool
+		expectedAttempts int
+	}{
+		{
+			name:             "no retry policy",
+			run:              flakyRun(1, errors.New("transient failure")),
+			expectedError:    true,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "transient failures are retried",
+			run:              flakyRun(2, errors.New("transient failure")),
+			retry:            &RetryPolicy{MaxAttempts: 3, Delay: time.Millisecond},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "attempts are exhausted",
+			run:              flakyRun(3, errors.New("transient failure")),
+			retry:            &RetryPolicy{MaxAttempts: 3, Backoff: BackoffExponential, Delay: time.Millisecond, Jitter: 0.5},
+			expectedError:    true,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "errors not retryable are not retried",
+			run:              flakyRun(1, errPermanent),
+			retry:            &RetryPolicy{MaxAttempts: 3, Delay: time.Millisecond, Retryable: notPermanent},
+			expectedError:    true,
+			expectedAttempts: 1,
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			var events []PhaseEvent
+			var w = Runner{
+				Phases: []Phase{{Name: "foo", Run: u.run, Retry: u.retry}},
+			}
+			w.AddObserver(ObserverFunc(func(event PhaseEvent) {
+				events = append(events, event)
+			}))
+
+			err := w.Run([]string{})
+			if (err != nil) != u.expectedError {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			last := events[len(events)-1]
+			if last.Attempt != u.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", u.expectedAttempts, last.Attempt)
+			}
+			retrying := 0
+			for _, event := range events {
+				if event.Type == PhaseRetrying {
+					retrying++
+				}
+			}
+			if retrying != u.expectedAttempts-1 {
+				t.Errorf("expected %d retrying events, got %d", u.expectedAttempts-1, retrying)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	var usecases = []struct {
+		name     string
+		policy   RetryPolicy
+		expected []time.Duration
+	}{
+		{
+			name:     "fixed",
+			policy:   RetryPolicy{Delay: time.Second},
+			expected: []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name:     "exponential",
+			policy:   RetryPolicy{Backoff: BackoffExponential, Delay: time.Second, MaxDelay: 3 * time.Second},
+			expected: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		{
+			name:     "exponential with custom multiplier",
+			policy:   RetryPolicy{Backoff: BackoffExponential, Delay: time.Second, Multiplier: 3},
+			expected: []time.Duration{time.Second, 3 * time.Second, 9 * time.Second},
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			var actual []time.Duration
+			for attempt := 2; attempt <= 4; attempt++ {
+				actual = append(actual, u.policy.delay(attempt))
+			}
+			if !reflect.DeepEqual(actual, u.expected) {
+				t.Errorf("\nactual:\n\t%v\nexpected:\n\t%v\n", actual, u.expected)
+			}
+		})
+	}
+
+	policy := RetryPolicy{Delay: time.Second, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		if d := policy.delay(2); d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatalf("delay %s out of the jitter range", d)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RollbackError is returned when a phase fails and the Rollback functions of the
+// phases already executed are invoked.
+type RollbackError struct {
+	// Err is the error of the failed phase.
+	Err error
+
+	// RolledBack is the list of the full names of the phases rolled back, in rollback order.
+	RolledBack []string
+
+	// RollbackErrs are the errors returned by the Rollback functions, if any.
+	RollbackErrs []error
+}
+
+func (e *RollbackError) Error() string {
+	if len(e.RollbackErrs) == 0 {
+		return fmt.Sprintf("%v (rolled back phases: %s)", e.Err, strings.Join(e.RolledBack, ", "))
+	}
+
+	msg := make([]string, 0, len(e.RollbackErrs))
+	for _, err := range e.RollbackErrs {
+		msg = append(msg, err.Error())
+	}
+	return fmt.Sprintf("%v (rollback failed: %s)", e.Err, strings.Join(msg, "; "))
+}
+
+func (e *RollbackError) Unwrap() error {
+	return e.Err
+}
+
+// rollback invokes the Rollback functions of the executed phases in reverse order.
+// All the Rollback functions are invoked even if some of them fail.
+// If no phase defines a Rollback function, the original error is returned as is.
+func (x *execution) rollback(err error) error {
+	x.mu.Lock()
+	executed := x.executed
+	x.mu.Unlock()
+
+	rollbackErr := &RollbackError{Err: err}
+	for i := len(executed) - 1; i >= 0; i-- {
+		p := executed[i]
+		if p.Rollback == nil {
+			continue
+		}
+		rollbackErr.RolledBack = append(rollbackErr.RolledBack, p.generatedName)
+		if err := p.Rollback(x.data); err != nil {
+			rollbackErr.RollbackErrs = append(rollbackErr.RollbackErrs, errors.Wrapf(err, "error rollback phase %s", p.generatedName))
+		}
+		// the phase should be executed again when resuming the workflow
+		if err := x.checkpoint.forget(p); err != nil {
+			rollbackErr.RollbackErrs = append(rollbackErr.RollbackErrs, err)
+		}
+	}
+
+	if len(rollbackErr.RolledBack) == 0 {
+		return err
+	}
+	return rollbackErr
+}
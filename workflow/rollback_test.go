@@ -0,0 +1,95 @@
+package workflow
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func rollbackBuilder(name string) func(data RunData) error {
+	return func(data RunData) error {
+		callstack = append(callstack, "rollback "+name)
+		return nil
+	}
+}
+
+func TestRunRollback(t *testing.T) {
+	var usecases = []struct {
+		name                 string
+		phases               []Phase
+		expectedOrder        []string
+		expectedRolledBack   []string
+		expectedRollbackErrs int
+	}{
+		{
+			name: "executed phases are rolled back in reverse order",
+			phases: []Phase{
+				{Name: "foo", Run: runBuilder("foo"), Rollback: rollbackBuilder("foo"),
+					Phases: []Phase{
+						{Name: "bar", Run: runBuilder("bar"), Rollback: rollbackBuilder("bar")},
+					},
+				},
+				{Name: "baz", Run: runBuilder("baz")},
+				{Name: "qux", RunIf: runConditionFalse, Run: runBuilder("qux"), Rollback: rollbackBuilder("qux")},
+				{Name: "quux", Run: runFails, Rollback: rollbackBuilder("quux")},
+				{Name: "corge", Run: runBuilder("corge"), Rollback: rollbackBuilder("corge")},
+			},
+			expectedOrder:      []string{"foo", "bar", "baz", "rollback bar", "rollback foo"},
+			expectedRolledBack: []string{"foo/bar", "foo"},
+		},
+		{
+			name: "all the rollback functions are invoked even if some fail",
+			phases: []Phase{
+				{Name: "foo", Run: runBuilder("foo"), Rollback: rollbackBuilder("foo")},
+				{Name: "bar", Run: runBuilder("bar"), Rollback: func(data RunData) error {
+					return errors.New("rollback fails")
+				}},
+				{Name: "baz", Run: runFails},
+			},
+			expectedOrder:        []string{"foo", "bar", "rollback foo"},
+			expectedRolledBack:   []string{"bar", "foo"},
+			expectedRollbackErrs: 1,
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			callstack = []string{}
+			var w = Runner{Phases: u.phases}
+
+			err := w.Run([]string{})
+			var rollbackErr *RollbackError
+			if !errors.As(err, &rollbackErr) {
+				t.Fatalf("expected a RollbackError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "run fails") {
+				t.Errorf("expected error to report the original failure, got %v", err)
+			}
+			if !reflect.DeepEqual(rollbackErr.RolledBack, u.expectedRolledBack) {
+				t.Errorf("\nrolled back:\n\t%v\nexpected:\n\t%v\n", rollbackErr.RolledBack, u.expectedRolledBack)
+			}
+			if len(rollbackErr.RollbackErrs) != u.expectedRollbackErrs {
+				t.Errorf("expected %d rollback errors, got %v", u.expectedRollbackErrs, rollbackErr.RollbackErrs)
+			}
+			if !reflect.DeepEqual(callstack, u.expectedOrder) {
+				t.Errorf("\ncallstack:\n\t%v\nexpected:\n\t%v\n", callstack, u.expectedOrder)
+			}
+		})
+	}
+}
+
+func TestRunWithoutRollback(t *testing.T) {
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder2("foo", nil, runPass),
+			phaseBuilder2("bar", nil, runFails),
+		},
+	}
+
+	err := w.Run([]string{})
+	var rollbackErr *RollbackError
+	if errors.As(err, &rollbackErr) {
+		t.Errorf("expected the original error when no phase defines Rollback, got %v", err)
+	}
+}
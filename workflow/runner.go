@@ -17,26 +17,95 @@ limitations under the License.
 package workflow
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
 )
 
 // phaseSeparator defines the separator to be used when concatenating nested
 // phase names
 const phaseSeparator = "/"
 
+// DefaultSkipLogVerbosity defines the default klog verbosity of the log lines for skipped phases.
+const DefaultSkipLogVerbosity klog.Level = 1
+
+// ErrSkipPhase is returned by RunnerOptions.BeforePhase for skipping the phase action.
+var ErrSkipPhase = errors.New("skip phase")
+
 // RunnerOptions defines the options supported during the execution of a
 // kubeadm composable workflows
 type RunnerOptions struct {
 	// FilterPhases defines the list of phases to be executed (if empty, all).
+	// Phases are selected by full name, glob pattern (e.g. "certs/*", "**/etcd*"), regular expression
+	// (e.g. "re:certs/(ca|sa)") or negated selector (e.g. "!certs/sa").
 	FilterPhases []string
 
 	// SkipPhases defines the list of phases to be excluded by execution (if empty, none).
+	// Phases are selected with the same syntax of FilterPhases.
 	SkipPhases []string
+
+	// FilterTags defines the list of tags of the phases to be executed, in addition to the
+	// phases selected by FilterPhases (if both are empty, all).
+	FilterTags []string
+
+	// SkipTags defines the list of tags of the phases to be excluded by execution, in addition
+	// to the phases selected by SkipPhases.
+	SkipTags []string
+
+	// Parallel enables the concurrent execution of independent phases.
+	// Each phase is executed after its parent phase and the phases listed in its Dependencies;
+	// phases without a relation among them can run at the same time, so they must not
+	// perform unsynchronized access to the RunData.
+	Parallel bool
+
+	// MaxParallelism defines the maximum number of phases executed concurrently
+	// when Parallel is set (if zero, no limit).
+	MaxParallelism int
+
+	// CheckpointFile defines the file where the completed phases are recorded (if empty, no checkpoint).
+	// The checkpoint file is removed once all the phases in the workflow are completed.
+	CheckpointFile string
+
+	// CheckpointKey identifies the configuration of the workflow, e.g. a hash of the config file;
+	// a checkpoint recorded with a different key is discarded when resuming.
+	CheckpointKey string
+
+	// Resume defines if the phases already completed according to the CheckpointFile should be skipped.
+	Resume bool
+
+	// DryRun defines if the runner should only compute and print the execution plan, without
+	// executing the phase actions; phases implementing DryRun are invoked instead.
+	DryRun bool
+
+	// SkipLogVerbosity defines the klog verbosity of the log lines explaining why a phase
+	// is skipped; NewRunner sets it to DefaultSkipLogVerbosity, while zero logs the lines
+	// at the default verbosity.
+	SkipLogVerbosity klog.Level
+
+	// BeforePhase defines a function invoked before executing the action of each phase, e.g. for
+	// asking the user a confirmation; if the function returns ErrSkipPhase the phase action is
+	// skipped, while any other error stops the workflow.
+	// The function is not invoked for hidden phases, that are internal to the workflow.
+	// Nb. nested phases are not skipped together with their parent phase, and when Parallel is set
+	// the function could be invoked concurrently.
+	BeforePhase func(phase string) error
+
+	// ReportStore defines if the content of the store is included in the report of the execution.
+	// Nb. the store could contain sensitive values, e.g. tokens, that are reported in clear text.
+	ReportStore bool
+
+	// IncludeDependencies defines if the phases listed in FilterPhases should be executed together
+	// with the phases they depend on, transitively, instead of failing because of unresolved dependencies.
+	IncludeDependencies bool
 }
 
 // RunData defines the data shared among all the phases included in the workflow, that is any type.
@@ -71,6 +140,24 @@ type Runner struct {
 	// a list of wrappers to phases composing the workflow with contextual
 	// information supporting phase execution.
 	phaseRunners []*phaseRunner
+
+	// out is the writer used for printing the execution plan in dry-run mode.
+	out io.Writer
+
+	// lastPlan is the execution plan computed by the last dry run.
+	lastPlan *ExecutionPlan
+
+	// observers are notified about the lifecycle events of the phases.
+	observers []Observer
+
+	// lastReport is the report of the last execution of the workflow.
+	lastReport *Report
+
+	// graphCommand defines if BindToCommand should add the phase graph subcommand.
+	graphCommand bool
+
+	// store is the store of the current, or of the last, execution of the workflow.
+	store *Store
 }
 
 // phaseRunner provides a wrapper to a Phase with the addition of a set
@@ -98,12 +185,19 @@ type phaseRunner struct {
 	// use is the phase usage string that will be printed in the workflow help.
 	// It corresponds to the relative path of the phase in the workflow managed by the Runner.
 	use string
+
+	// paths are all the absolute paths of the phase, starting from generatedName, obtained by
+	// using the aliases of the phase and of its parent phases in place of their names.
+	paths []string
 }
 
 // NewRunner return a new runner for composable kubeadm workflows.
 func NewRunner() *Runner {
 	return &Runner{
 		Phases: []Phase{},
+		Options: RunnerOptions{
+			SkipLogVerbosity: DefaultSkipLogVerbosity,
+		},
 	}
 }
 
@@ -115,12 +209,33 @@ func (e *Runner) AppendPhase(t Phase) {
 // computePhaseRunFlags return a map defining which phase should be run and which not.
 // PhaseRunFlags are computed according to RunnerOptions.
 func (e *Runner) computePhaseRunFlags() (map[string]bool, error) {
-	// Initialize support data structure
+	skipReasons, _, err := e.computeSkipReasons(e.Options)
+	if err != nil {
+		return nil, err
+	}
+	return runFlags(skipReasons), nil
+}
+
+// runFlags converts the given skipReasons into a map defining which phase should be run and which not.
+func runFlags(skipReasons map[string]SkipReason) map[string]bool {
 	phaseRunFlags := map[string]bool{}
+	for name, reason := range skipReasons {
+		phaseRunFlags[name] = reason == ""
+	}
+	return phaseRunFlags
+}
+
+// computeSkipReasons return a map defining, for each phase, the reason why the phase
+// should not be run; an empty reason means that the phase should be run.
+// SkipReasons are computed according to the given RunnerOptions; if RunnerOptions.IncludeDependencies
+// is set, the phases included because of the dependencies of the filtered phases are returned as well.
+func (e *Runner) computeSkipReasons(options RunnerOptions) (map[string]SkipReason, map[string]bool, error) {
+	// Initialize support data structure
+	skipReasons := map[string]SkipReason{}
 	phaseHierarchy := map[string][]string{}
 	e.visitAll(func(p *phaseRunner) error {
-		// Initialize phaseRunFlags assuming that all the phases should be run.
-		phaseRunFlags[p.generatedName] = true
+		// Initialize skipReasons assuming that all the phases should be run.
+		skipReasons[p.generatedName] = ""
 
 		// Initialize phaseHierarchy for the current phase (the list of phases
 		// depending on the current phase
@@ -135,36 +250,67 @@ func (e *Runner) computePhaseRunFlags() (map[string]bool, error) {
 		return nil
 	})
 
-	// If a filter option is specified, set all phaseRunFlags to false except for
-	// the phases included in the filter and their hierarchy of nested phases.
-	if len(e.Options.FilterPhases) > 0 {
-		for i := range phaseRunFlags {
-			phaseRunFlags[i] = false
+	// If a filter option is specified, mark all the phases as filtered except for
+	// the phases selected by the filter and their hierarchy of nested phases.
+	filterPhases := append(append([]string{}, options.FilterPhases...), tagSelectors(options.FilterTags)...)
+	if len(filterPhases) > 0 {
+		selected, err := e.selectPhases(filterPhases, phaseHierarchy)
+		if err != nil {
+			return skipReasons, nil, err
 		}
-		for _, f := range e.Options.FilterPhases {
-			if _, ok := phaseRunFlags[f]; !ok {
-				return phaseRunFlags, errors.Errorf("invalid phase name: %s", f)
+		for i := range skipReasons {
+			if !selected[i] {
+				skipReasons[i] = SkipReasonFiltered
 			}
-			phaseRunFlags[f] = true
-			for _, c := range phaseHierarchy[f] {
-				phaseRunFlags[c] = true
+		}
+	}
+
+	// If requested, include the phases the filtered phases depend on, together with
+	// their hierarchy of nested phases, until all the dependencies are included.
+	included := map[string]bool{}
+	if len(filterPhases) > 0 && options.IncludeDependencies {
+		queue := []*phaseRunner{}
+		e.visitAll(func(p *phaseRunner) error {
+			if skipReasons[p.generatedName] == "" {
+				queue = append(queue, p)
+			}
+			return nil
+		})
+		for len(queue) > 0 {
+			p := queue[0]
+			queue = queue[1:]
+			for _, dep := range p.Dependencies {
+				r := e.resolveDependency(p, dep)
+				if r == nil || skipReasons[r.generatedName] == "" {
+					continue
+				}
+				for _, name := range append([]string{r.generatedName}, phaseHierarchy[r.generatedName]...) {
+					if skipReasons[name] == "" {
+						continue
+					}
+					skipReasons[name] = ""
+					included[name] = true
+					queue = append(queue, e.lookupPhaseRunner(name))
+				}
 			}
 		}
 	}
 
-	// If a phase skip option is specified, set the corresponding phaseRunFlags
-	// to false and apply the same change to the underlying hierarchy
-	for _, f := range e.Options.SkipPhases {
-		if _, ok := phaseRunFlags[f]; !ok {
-			return phaseRunFlags, errors.Errorf("invalid phase name: %s", f)
+	// If a phase skip option is specified, mark the selected phases as skipped
+	// and apply the same change to the underlying hierarchy
+	skipPhases := append(append([]string{}, options.SkipPhases...), tagSelectors(options.SkipTags)...)
+	if len(skipPhases) > 0 {
+		selected, err := e.selectPhases(skipPhases, phaseHierarchy)
+		if err != nil {
+			return skipReasons, nil, err
 		}
-		phaseRunFlags[f] = false
-		for _, c := range phaseHierarchy[f] {
-			phaseRunFlags[c] = false
+		for name := range selected {
+			skipReasons[name] = SkipReasonSkipped
+			delete(included, name)
 		}
 	}
 
-	return phaseRunFlags, nil
+	return skipReasons, included, nil
 }
 
 // SetDataInitializer allows to setup a function that initialize the runtime data shared
@@ -190,35 +336,36 @@ func (e *Runner) InitData(args []string) (RunData, error) {
 
 // Run the kubeadm composable kubeadm workflows.
 func (e *Runner) Run(args []string) error {
+	return e.RunContext(context.Background(), args)
+}
+
+// RunContext runs the kubeadm composable kubeadm workflows using the given context.
+// When the context is canceled (or the timeout of a phase expires) the running phase is
+// interrupted, no further phases are executed and a *PhaseCanceledError is returned.
+func (e *Runner) RunContext(ctx context.Context, args []string) (err error) {
 	e.prepareForExecution()
+	e.lastReport = nil
 
 	// determine which phase should be run according to RunnerOptions
-	phaseRunFlags, err := e.computePhaseRunFlags()
+	skipReasons, included, err := e.computeSkipReasons(e.Options)
 	if err != nil {
 		return err
 	}
+	phaseRunFlags := runFlags(skipReasons)
 
 	// precheck phase dependencies before actual execution
-	missedDeps := make(map[string][]string)
-	visited := make(map[string]struct{})
-	for _, p := range e.phaseRunners {
-		if run, ok := phaseRunFlags[p.generatedName]; !run || !ok {
-			continue
-		}
-		for _, dep := range p.Phase.Dependencies {
-			if _, ok := visited[dep]; !ok {
-				missedDeps[p.Phase.Name] = append(missedDeps[p.Phase.Name], dep)
-			}
-		}
-		visited[p.Phase.Name] = struct{}{}
+	if err := e.ValidateDependencies(); err != nil {
+		return err
 	}
-	if len(missedDeps) > 0 {
-		var msg strings.Builder
-		msg.WriteString("unresolved dependencies:")
-		for phase, missedPhases := range missedDeps {
-			msg.WriteString(fmt.Sprintf("\n\tmissing %v phase(s) needed by %q phase", missedPhases, phase))
-		}
-		return errors.New(msg.String())
+	phaseDependencies, err := e.resolveDependencies(phaseRunFlags)
+	if err != nil {
+		return err
+	}
+
+	// loads the checkpoint of the previous execution, if any
+	checkpoint, err := newCheckpointRecorder(e.Options)
+	if err != nil {
+		return err
 	}
 
 	// builds the runner data if the runtime data is not initialized
@@ -229,42 +376,198 @@ func (e *Runner) Run(args []string) error {
 		}
 	}
 
-	err = e.visitAll(func(p *phaseRunner) error {
-		// if the phase should not be run, skip the phase.
-		if run, ok := phaseRunFlags[p.generatedName]; !run || !ok {
+	// the store is restored from the checkpoint, if any, and made available to the phases via ctx
+	e.store = checkpoint.restoreStore()
+	ctx = WithStore(ctx, e.store)
+
+	x := &execution{ctx: ctx, data: data, checkpoint: checkpoint, observers: e.observers, store: e.store, skipLogVerbosity: e.Options.SkipLogVerbosity}
+	if e.Options.DryRun {
+		return e.dryRun(x)
+	}
+
+	// records the report of the execution
+	report := newReportRecorder(e.phaseRunners, included)
+	x.observers = append(append([]Observer{}, e.observers...), report)
+	defer func() {
+		store := x.store
+		if !e.Options.ReportStore {
+			store = nil
+		}
+		e.lastReport = report.finalize(err, store)
+	}()
+
+	run := func(p *phaseRunner) error {
+		// if the phase was completed by a previous execution, skip the phase.
+		if checkpoint.isCompleted(p) {
+			x.phaseSkipped(p, SkipReasonCompleted, "")
 			return nil
 		}
 
-		// Errors if phases that are meant to create special subcommands only
-		// are wrongly assigned Run Methods
-		if p.RunAllSiblings && (p.RunIf != nil || p.Run != nil) {
-			return errors.Errorf("phase marked as RunAllSiblings can not have Run functions %s", p.generatedName)
+		executed, err := e.executePhase(x, p)
+		if err != nil || !executed {
+			// phases skipped by RunIf or BeforePhase are not completed, so they are run when resuming
+			return err
 		}
+		return checkpoint.record(p, x.store)
+	}
 
-		// If the phase defines a condition to be checked before executing the phase action.
-		if p.RunIf != nil {
-			// Check the condition and returns if the condition isn't satisfied (or fails)
-			ok, err := p.RunIf(data)
-			if err != nil {
-				return errors.Wrapf(err, "error execution run condition for phase %s", p.generatedName)
+	if e.Options.Parallel {
+		// notifies the phases that should not be run before starting the others.
+		e.visitAll(func(p *phaseRunner) error {
+			if reason := skipReasons[p.generatedName]; reason != "" {
+				x.phaseSkipped(p, reason, "")
 			}
-
-			if !ok {
+			return nil
+		})
+		err = e.runParallel(phaseRunFlags, phaseDependencies, run)
+	} else {
+		err = e.visitAll(func(p *phaseRunner) error {
+			// if the phase should not be run, skip the phase.
+			if run, ok := phaseRunFlags[p.generatedName]; !run || !ok {
+				x.phaseSkipped(p, skipReasons[p.generatedName], "")
 				return nil
 			}
+
+			return run(p)
+		})
+	}
+	if err != nil {
+		// undoes the changes applied by the phases executed so far
+		err = x.rollback(err)
+		return err
+	}
+
+	err = checkpoint.finalize(e.phaseRunners)
+	return err
+}
+
+// executePhase checks the run condition of the given phase and then runs the phase action.
+// It returns false if the phase action was skipped, because the run condition isn't satisfied
+// or because of BeforePhase.
+func (e *Runner) executePhase(x *execution, p *phaseRunner) (bool, error) {
+	// stops the workflow if the context was canceled in the meantime
+	if err := x.ctx.Err(); err != nil {
+		return false, x.phaseFailed(p, time.Now(), 0, &PhaseCanceledError{Phase: p.generatedName, Err: err})
+	}
+
+	// Errors if phases that are meant to create special subcommands only
+	// are wrongly assigned Run Methods
+	if p.RunAllSiblings && (p.conditional() || p.Run != nil || p.RunContext != nil || p.Rollback != nil || p.DryRun != nil) {
+		return false, x.phaseFailed(p, time.Now(), 0, errors.Errorf("phase marked as RunAllSiblings can not have Run functions %s", p.generatedName))
+	}
+
+	// If the phase defines a condition to be checked before executing the phase action.
+	if p.conditional() {
+		// Check the condition and returns if the condition isn't satisfied (or fails)
+		ok, message, err := p.checkCondition(x.data)
+		if err != nil {
+			return false, x.phaseFailed(p, time.Now(), 0, errors.Wrapf(err, "error execution run condition for phase %s", p.generatedName))
+		}
+
+		if !ok {
+			x.phaseSkipped(p, SkipReasonConditionFalse, message)
+			x.checkpoint.skipConditionFalse(p)
+			return false, nil
 		}
+	}
 
-		// Runs the phase action (if defined)
-		if p.Run != nil {
-			if err := p.Run(data); err != nil {
-				return errors.Wrapf(err, "error execution phase %s", p.generatedName)
+	// If requested, checks if the phase action should be executed
+	if e.Options.BeforePhase != nil && !p.Hidden && (p.Run != nil || p.RunContext != nil) {
+		if err := e.Options.BeforePhase(p.generatedName); err != nil {
+			if errors.Is(err, ErrSkipPhase) {
+				x.phaseSkipped(p, SkipReasonUser, "")
+				return false, nil
 			}
+			return false, x.phaseFailed(p, time.Now(), 0, err)
 		}
+	}
 
-		return nil
-	})
+	// Runs the phase action (if defined)
+	start := x.phaseStarted(p)
+	attempts, err := e.runPhaseWithRetry(x, p)
+	if err != nil {
+		return false, x.phaseFailed(p, start, attempts, err)
+	}
+	x.markExecuted(p)
+	x.phaseSucceeded(p, start, attempts)
+	return true, nil
+}
 
-	return err
+// cancelGracePeriod defines how long runPhase waits for the phase action to return
+// after the context is canceled.
+var cancelGracePeriod = 5 * time.Second
+
+// runPhase executes the action of the given phase, if any, enforcing the phase timeout
+// and returning early when the context is canceled.
+// After the context is canceled, runPhase waits for the phase action to return for at most
+// cancelGracePeriod, so the following phases and rollbacks usually do not run concurrently with it.
+// Nb. a phase implementing only Run can not be stopped, so after the grace period it is left running in background.
+func (e *Runner) runPhase(ctx context.Context, p *phaseRunner, data RunData) error {
+	run := p.RunContext
+	if run == nil {
+		if p.Run == nil {
+			return nil
+		}
+		run = func(_ context.Context, data RunData) error {
+			return p.Run(data)
+		}
+	}
+
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- run(ctx, data)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		grace := time.NewTimer(cancelGracePeriod)
+		defer grace.Stop()
+		select {
+		case <-errCh:
+		case <-grace.C:
+			klog.Warningf("phase %s is still running after the workflow was interrupted", p.generatedName)
+		}
+		return &PhaseCanceledError{Phase: p.generatedName, Err: ctx.Err()}
+	}
+
+	if err != nil {
+		// context-aware phases usually return the context error when interrupted
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			return &PhaseCanceledError{Phase: p.generatedName, Err: ctxErr}
+		}
+		return errors.Wrapf(err, "error execution phase %s", p.generatedName)
+	}
+	return nil
+}
+
+// PhaseCanceledError is returned when the execution of a phase is interrupted because
+// the workflow context was canceled or because the phase timeout expired.
+// The wrapped error is either context.Canceled or context.DeadlineExceeded.
+type PhaseCanceledError struct {
+	// Phase is the full name of the interrupted phase.
+	Phase string
+
+	// Err is the context error that caused the interruption.
+	Err error
+}
+
+func (e *PhaseCanceledError) Error() string {
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return fmt.Sprintf("phase %s timed out", e.Phase)
+	}
+	return fmt.Sprintf("phase %s canceled", e.Phase)
+}
+
+func (e *PhaseCanceledError) Unwrap() error {
+	return e.Err
 }
 
 // Help returns text with the list of phases included in the workflow.
@@ -295,6 +598,9 @@ func (e *Runner) Help(cmdUse string) string {
 			line += p.use                               // name + aliases
 			line += strings.Repeat(" ", padding)        // padding right up to max length (+ offset for spacing)
 			line += p.Short                             // phase short description
+			if len(p.Tags) > 0 {
+				line += fmt.Sprintf(" (tags: %s)", strings.Join(p.Tags, ", ")) // phase tags
+			}
 			line += "\n"
 		}
 
@@ -304,6 +610,39 @@ func (e *Runner) Help(cmdUse string) string {
 	return line
 }
 
+// Tags returns the sorted list of the tags of the phases in the workflow.
+func (e *Runner) Tags() []string {
+	e.prepareForExecution()
+
+	seen := map[string]bool{}
+	tags := []string{}
+	e.visitAll(func(p *phaseRunner) error {
+		for _, t := range p.Tags {
+			if !seen[strings.ToLower(t)] {
+				seen[strings.ToLower(t)] = true
+				tags = append(tags, t)
+			}
+		}
+		return nil
+	})
+	sort.Strings(tags)
+	return tags
+}
+
+// SetOutput sets the destination for the execution plan printed in dry-run mode.
+// If output is nil, os.Stdout is used.
+func (e *Runner) SetOutput(output io.Writer) {
+	e.out = output
+}
+
+// OutOrStdout returns the output set by SetOutput or os.Stdout.
+func (e *Runner) OutOrStdout() io.Writer {
+	if e.out == nil {
+		return os.Stdout
+	}
+	return e.out
+}
+
 // SetAdditionalFlags allows to define flags to be added
 // to the subcommands generated for each phase (but not existing in the parent command).
 // Please note that this command needs to be done before BindToCommand.
@@ -369,10 +708,19 @@ func (e *Runner) BindToCommand(cmd *cobra.Command) {
 				// overrides the command triggering the Runner using the phaseCmd
 				e.runCmd = cmd
 				e.Options.FilterPhases = []string{phaseSelector}
-				return e.Run(args)
+				ctx := cmd.Context()
+				if ctx == nil {
+					ctx = context.Background()
+				}
+				return e.RunContext(ctx, args)
 			},
 		}
 
+		// if the phases selected by this command have dependencies, allows to run the phases they depend on as well
+		if len(p.Phases) == 0 && e.hasDependencies(phaseSelector) {
+			phaseCmd.Flags().BoolVar(&e.Options.IncludeDependencies, "with-deps", false, "Run the phases this phase depends on as well")
+		}
+
 		// makes the new command inherits local flags from the parent command
 		// Nb. global flags will be inherited automatically
 		inheritsFlags(cmd.Flags(), phaseCmd.Flags(), p.InheritFlags)
@@ -398,6 +746,10 @@ func (e *Runner) BindToCommand(cmd *cobra.Command) {
 			} else {
 				phaseCmd.Args = p.ArgsValidator
 			}
+
+			// makes the new command complete args like the parent command
+			phaseCmd.ValidArgs = cmd.ValidArgs
+			phaseCmd.ValidArgsFunction = cmd.ValidArgsFunction
 		}
 
 		// adds the command to parent
@@ -411,6 +763,11 @@ func (e *Runner) BindToCommand(cmd *cobra.Command) {
 		return nil
 	})
 
+	// if requested, adds the subcommand printing the graph of the workflow
+	if e.graphCommand {
+		e.addGraphCommand(phaseCommand)
+	}
+
 	// alters the command description to show available phases
 	if cmd.Long != "" {
 		cmd.Long = fmt.Sprintf("%s\n\n%s\n", cmd.Long, e.Help(cmd.Use))
@@ -419,7 +776,20 @@ func (e *Runner) BindToCommand(cmd *cobra.Command) {
 	}
 
 	// adds phase related flags to the main command
-	cmd.Flags().StringSliceVar(&e.Options.SkipPhases, "skip-phases", nil, "List of phases to be skipped")
+	cmd.Flags().StringSliceVar(&e.Options.SkipPhases, "skip-phases", nil, "List of phases to be skipped, by name, glob (e.g. certs/*), regexp (e.g. re:certs/(ca|sa)) or negation (e.g. !certs/ca)")
+	cmd.Flags().StringSliceVar(&e.Options.FilterPhases, "only-phases", nil, "List of phases to be executed, skipping all the others; phases are selected as in --skip-phases")
+
+	// adds --phases as an alias of --only-phases, sharing the same value
+	onlyPhases := cmd.Flags().Lookup("only-phases")
+	cmd.Flags().AddFlag(&pflag.Flag{
+		Name:     "phases",
+		Usage:    "Alias of --only-phases",
+		Value:    onlyPhases.Value,
+		DefValue: onlyPhases.DefValue,
+	})
+
+	// completes the phase names for the flags selecting phases
+	e.registerPhaseNamesCompletion(cmd, "skip-phases", "only-phases", "phases")
 }
 
 func inheritsFlags(sourceFlags, targetFlags *pflag.FlagSet, cmdFlags []string) {
@@ -478,6 +848,21 @@ func addPhaseRunner(e *Runner, parentRunner *phaseRunner, phase Phase) {
 		selfPath = append(parentRunner.selfPath, selfPath...)
 	}
 
+	// computes the paths of the phase using names and aliases
+	names := []string{cleanName(phase.Name)}
+	for _, a := range phase.Aliases {
+		names = append(names, cleanName(a))
+	}
+	paths := names
+	if parentRunner != nil {
+		paths = []string{}
+		for _, parentPath := range parentRunner.paths {
+			for _, n := range names {
+				paths = append(paths, parentPath+phaseSeparator+n)
+			}
+		}
+	}
+
 	// creates the phaseRunner
 	currentRunner := &phaseRunner{
 		Phase:         phase,
@@ -486,6 +871,7 @@ func addPhaseRunner(e *Runner, parentRunner *phaseRunner, phase Phase) {
 		selfPath:      selfPath,
 		generatedName: generatedName,
 		use:           use,
+		paths:         paths,
 	}
 
 	// adds to the phaseRunners list
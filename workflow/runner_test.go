@@ -17,10 +17,13 @@ limitations under the License.
 package workflow
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -249,6 +252,184 @@ func TestRunHandleErrors(t *testing.T) {
 	}
 }
 
+func runBlocking(ctx context.Context, data RunData) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRunBeforePhase(t *testing.T) {
+	errAbort := errors.New("abort")
+	var w = Runner{
+		Phases: []Phase{
+			phaseBuilder1("foo", nil, phaseBuilder1("bar", nil)),
+			phaseBuilder1("baz", nil),
+			{Name: "hidden", Hidden: true, Run: func(data RunData) error {
+				callstack = append(callstack, "hidden")
+				return nil
+			}},
+			phaseBuilder1("qux", nil),
+		},
+	}
+	var asked []string
+	w.Options.BeforePhase = func(phase string) error {
+		asked = append(asked, phase)
+		switch phase {
+		case "foo":
+			return ErrSkipPhase
+		case "qux":
+			return errAbort
+		}
+		return nil
+	}
+
+	callstack = []string{}
+	err := w.Run([]string{})
+	if !errors.Is(err, errAbort) {
+		t.Errorf("expected abort error, got %v", err)
+	}
+	if !reflect.DeepEqual(asked, []string{"foo", "foo/bar", "baz", "qux"}) {
+		t.Errorf("expected confirmation for all the phases but the hidden one, got %v", asked)
+	}
+	if !reflect.DeepEqual(callstack, []string{"bar", "baz", "hidden"}) {
+		t.Errorf("expected callstack [bar baz hidden], got %v", callstack)
+	}
+	if entry := w.Report().Phases[0]; entry.Reason != SkipReasonUser {
+		t.Errorf("expected phase foo skipped by the user, got %v", entry.Reason)
+	}
+}
+
+func TestRunContextCancellation(t *testing.T) {
+	var usecases = []struct {
+		name          string
+		phases        []Phase
+		cancel        bool
+		expectedPhase string
+		expectedErr   error
+		expectedOrder []string
+	}{
+		{
+			name: "phase timeout expires",
+			phases: []Phase{
+				{Name: "foo", RunContext: runBlocking, Timeout: 10 * time.Millisecond},
+				phaseBuilder1("bar", nil),
+			},
+			expectedPhase: "foo",
+			expectedErr:   context.DeadlineExceeded,
+			expectedOrder: []string{},
+		},
+		{
+			name: "timeout does not affect fast phases",
+			phases: []Phase{
+				{Name: "foo", Run: runBuilder("foo"), Timeout: time.Minute},
+				phaseBuilder1("bar", nil),
+			},
+			expectedOrder: []string{"foo", "bar"},
+		},
+		{
+			name: "canceled context interrupts the running phase",
+			phases: []Phase{
+				phaseBuilder1("foo", nil),
+				{Name: "bar", RunContext: runBlocking},
+				phaseBuilder1("baz", nil),
+			},
+			cancel:        true,
+			expectedPhase: "bar",
+			expectedErr:   context.Canceled,
+			expectedOrder: []string{"foo"},
+		},
+		{
+			name: "phases implementing only Run are interrupted too",
+			phases: []Phase{
+				{Name: "foo", Run: func(data RunData) error {
+					time.Sleep(time.Second)
+					return nil
+				}, Timeout: 10 * time.Millisecond},
+			},
+			expectedPhase: "foo",
+			expectedErr:   context.DeadlineExceeded,
+			expectedOrder: []string{},
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			callstack = []string{}
+			var w = Runner{Phases: u.phases}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if u.cancel {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					cancel()
+				}()
+			}
+
+			err := w.RunContext(ctx, []string{})
+			if u.expectedErr == nil {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			} else {
+				var canceledErr *PhaseCanceledError
+				if !errors.As(err, &canceledErr) {
+					t.Fatalf("expected a PhaseCanceledError, got %v", err)
+				}
+				if canceledErr.Phase != u.expectedPhase {
+					t.Errorf("expected canceled phase %q, got %q", u.expectedPhase, canceledErr.Phase)
+				}
+				if !errors.Is(err, u.expectedErr) {
+					t.Errorf("expected error %v, got %v", u.expectedErr, err)
+				}
+			}
+			if !reflect.DeepEqual(callstack, u.expectedOrder) {
+				t.Errorf("\ncallstack:\n\t%v\nexpected:\n\t%v\n", callstack, u.expectedOrder)
+			}
+		})
+	}
+}
+
+func TestRunPhaseCancelGracePeriod(t *testing.T) {
+	var usecases = []struct {
+		name             string
+		gracePeriod      time.Duration
+		expectedFinished bool
+	}{
+		{
+			name:             "waits for Run within the grace period",
+			gracePeriod:      time.Second,
+			expectedFinished: true,
+		},
+		{
+			name:             "leaves Run in background after the grace period",
+			gracePeriod:      time.Millisecond,
+			expectedFinished: false,
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			defer func(d time.Duration) { cancelGracePeriod = d }(cancelGracePeriod)
+			cancelGracePeriod = u.gracePeriod
+
+			var finished atomic.Bool
+			var w = Runner{Phases: []Phase{
+				{Name: "foo", Run: func(data RunData) error {
+					time.Sleep(100 * time.Millisecond)
+					finished.Store(true)
+					return nil
+				}, Timeout: 10 * time.Millisecond},
+			}}
+
+			err := w.RunContext(context.Background(), []string{})
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected error %v, got %v", context.DeadlineExceeded, err)
+			}
+			if finished.Load() != u.expectedFinished {
+				t.Errorf("expected Run finished %t, got %t", u.expectedFinished, finished.Load())
+			}
+		})
+	}
+}
+
 func phaseBuilder3(name string, hidden bool, phases ...Phase) Phase {
 	return Phase{
 		Name:   name,
@@ -282,6 +463,29 @@ func TestHelp(t *testing.T) {
 	}
 }
 
+func TestHelpTags(t *testing.T) {
+	var w = Runner{
+		Phases: []Phase{
+			{Name: "foo", Short: "long description for foo ...", Tags: []string{"network", "slow"}},
+			phaseBuilder3("bar", false),
+		},
+	}
+
+	expected := "The \"myCommand\" command executes the following phases:\n" +
+		"```\n" +
+		"foo  long description for foo ... (tags: network, slow)\n" +
+		"bar  long description for bar ...\n" +
+		"```"
+
+	actual := w.Help("myCommand")
+	if actual != expected {
+		t.Errorf("\nactual:\n\t%v\nexpected:\n\t%v\n", actual, expected)
+	}
+	if tags := w.Tags(); !reflect.DeepEqual(tags, []string{"network", "slow"}) {
+		t.Errorf("expected tags [network slow], got %v", tags)
+	}
+}
+
 func phaseBuilder4(name string, cmdFlags []string, phases ...Phase) Phase {
 	return Phase{
 		Name:         name,
@@ -0,0 +1,226 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Phase selectors are used in RunnerOptions.FilterPhases and RunnerOptions.SkipPhases for
+// selecting phases by full name; aliases can be used in place of the names of the phases, e.g.
+// "certs/root-ca" for the "ca" phase with alias "root-ca". The following syntaxes are supported:
+//   - an exact full name, e.g. "certs/ca"
+//   - a glob pattern, where "*" and "?" match within a path element and "**" matches any
+//     number of path elements, e.g. "certs/*" or "**/etcd*"
+//   - a regular expression matching the whole full name, prefixed by "re:", e.g. "re:certs/(ca|sa)"
+//   - a tag of the phases, prefixed by "tag:", e.g. "tag:network"
+//
+// A selector prefixed by "!" excludes the phases it matches from the ones selected by the other
+// selectors, e.g. "certs/*", "!certs/sa"; if all the selectors are negated, they exclude phases from
+// the whole workflow. Phases selected by a selector include their hierarchy of nested phases.
+// Names, glob patterns and regular expressions are matched case-insensitively.
+const (
+	selectorNegation = "!"
+	selectorRegexp   = "re:"
+	selectorTag      = "tag:"
+)
+
+// phaseSelector matches the full names of the phases.
+type phaseSelector struct {
+	negated bool
+	exact   string
+	tag     string
+	re      *regexp.Regexp
+}
+
+// parsePhaseSelector parses a phase selector.
+func parsePhaseSelector(s string) (*phaseSelector, error) {
+	sel := &phaseSelector{}
+	expr := strings.TrimSpace(s)
+	if strings.HasPrefix(expr, selectorNegation) {
+		sel.negated = true
+		expr = strings.TrimPrefix(expr, selectorNegation)
+	}
+	if expr == "" {
+		return nil, errors.Errorf("invalid phase selector %q: empty selector", s)
+	}
+
+	switch {
+	case strings.HasPrefix(expr, selectorTag):
+		sel.tag = strings.TrimPrefix(expr, selectorTag)
+	case strings.HasPrefix(expr, selectorRegexp):
+		re, err := regexp.Compile("(?i)^(?:" + strings.TrimPrefix(expr, selectorRegexp) + ")$")
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid phase selector %q", s)
+		}
+		sel.re = re
+	case strings.ContainsAny(expr, "*?"):
+		sel.re = regexp.MustCompile("^" + globToRegexp(strings.ToLower(expr)) + "$")
+	default:
+		sel.exact = strings.ToLower(expr)
+	}
+	return sel, nil
+}
+
+// globToRegexp converts a glob pattern into a regular expression.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**"+phaseSeparator):
+			b.WriteString("(?:.*" + phaseSeparator + ")?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			b.WriteString("[^" + phaseSeparator + "]*")
+		case glob[i] == '?':
+			b.WriteString("[^" + phaseSeparator + "]")
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// matches returns true if the selector matches the given phase.
+func (s *phaseSelector) matches(p *phaseRunner) bool {
+	switch {
+	case s.tag != "":
+		return p.hasTag(s.tag)
+	}
+	for _, path := range p.paths {
+		if (s.re != nil && s.re.MatchString(path)) || s.exact == path {
+			return true
+		}
+	}
+	return false
+}
+
+// selectPhases returns the set of the full names of the phases selected by the given selectors,
+// including their hierarchy of nested phases.
+// Each selector must match at least one phase of the workflow.
+func (e *Runner) selectPhases(selectors []string, phaseHierarchy map[string][]string) (map[string]bool, error) {
+	included := map[string]bool{}
+	excluded := map[string]bool{}
+	onlyNegated := true
+	for _, s := range selectors {
+		sel, err := parsePhaseSelector(s)
+		if err != nil {
+			return nil, err
+		}
+		if !sel.negated {
+			onlyNegated = false
+		}
+
+		matched := false
+		for _, p := range e.phaseRunners {
+			if !sel.matches(p) {
+				continue
+			}
+			matched = true
+			target := included
+			if sel.negated {
+				target = excluded
+			}
+			target[p.generatedName] = true
+			for _, c := range phaseHierarchy[p.generatedName] {
+				target[c] = true
+			}
+		}
+		if !matched {
+			return nil, e.unknownSelectorError(s, sel)
+		}
+	}
+
+	if onlyNegated {
+		for _, p := range e.phaseRunners {
+			included[p.generatedName] = true
+		}
+	}
+	for name := range excluded {
+		delete(included, name)
+	}
+	return included, nil
+}
+
+// unknownSelectorError returns the error for a selector that doesn't match any phase, suggesting
+// the full names of the phases similar to the selector, if any.
+func (e *Runner) unknownSelectorError(s string, sel *phaseSelector) error {
+	if sel.tag != "" {
+		return errors.Errorf("invalid phase tag %q, known tags: [%s]", sel.tag, strings.Join(e.Tags(), ", "))
+	}
+	if sel.exact == "" {
+		return errors.Errorf("invalid phase selector %q: no phase matches it", s)
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	maxDistance := len(sel.exact)/3 + 1
+	for _, p := range e.phaseRunners {
+		d := levenshtein(sel.exact, p.generatedName)
+		// matches also the last path element, e.g. "ca" for "certs/ca"
+		if last := p.selfPath[len(p.selfPath)-1]; last != p.generatedName {
+			if dl := levenshtein(sel.exact, last); dl < d {
+				d = dl
+			}
+		}
+		if d <= maxDistance {
+			candidates = append(candidates, candidate{name: p.generatedName, distance: d})
+		}
+	}
+	if len(candidates) == 0 {
+		return errors.Errorf("invalid phase name: %s", s)
+	}
+
+	// suggests the closest phases, up to three
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	var suggestions []string
+	for _, c := range candidates {
+		if c.distance > candidates[0].distance || len(suggestions) == 3 {
+			break
+		}
+		suggestions = append(suggestions, fmt.Sprintf("%q", c.name))
+	}
+	return errors.Errorf("invalid phase name: %s, did you mean %s?", s, strings.Join(suggestions, " or "))
+}
+
+// tagSelectors returns the selectors for the given tags.
+func tagSelectors(tags []string) []string {
+	selectors := make([]string, 0, len(tags))
+	for _, t := range tags {
+		selectors = append(selectors, selectorTag+t)
+	}
+	return selectors
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,113 @@
+package workflow
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComputePhaseRunFlagsSelectors(t *testing.T) {
+	var usecases = []struct {
+		name          string
+		options       RunnerOptions
+		expected      map[string]bool
+		expectedError string
+	}{
+		{
+			name:     "glob matches within a path element",
+			options:  RunnerOptions{FilterPhases: []string{"certs/*"}},
+			expected: map[string]bool{"certs": false, "certs/ca": true, "certs/etcd-ca": true, "etcd": false, "etcd/local": false},
+		},
+		{
+			name:     "double star glob matches any number of path elements",
+			options:  RunnerOptions{FilterPhases: []string{"**/etcd*"}},
+			expected: map[string]bool{"certs": false, "certs/ca": false, "certs/etcd-ca": true, "etcd": true, "etcd/local": true},
+		},
+		{
+			name:     "regular expression",
+			options:  RunnerOptions{SkipPhases: []string{"re:certs/(etcd-)?ca"}},
+			expected: map[string]bool{"certs": true, "certs/ca": false, "certs/etcd-ca": false, "etcd": true, "etcd/local": true},
+		},
+		{
+			name:     "regular expression is case-insensitive",
+			options:  RunnerOptions{FilterPhases: []string{"re:CERTS/C."}},
+			expected: map[string]bool{"certs": false, "certs/ca": true, "certs/etcd-ca": false, "etcd": false, "etcd/local": false},
+		},
+		{
+			name:     "negation excludes phases from the selection",
+			options:  RunnerOptions{SkipPhases: []string{"certs", "!certs/ca"}},
+			expected: map[string]bool{"certs": false, "certs/ca": true, "certs/etcd-ca": false, "etcd": true, "etcd/local": true},
+		},
+		{
+			name:     "only negations exclude phases from the whole workflow",
+			options:  RunnerOptions{FilterPhases: []string{"!etcd"}},
+			expected: map[string]bool{"certs": true, "certs/ca": true, "certs/etcd-ca": true, "etcd": false, "etcd/local": false},
+		},
+		{
+			name:     "tags select phases across subtrees",
+			options:  RunnerOptions{FilterTags: []string{"etcd"}},
+			expected: map[string]bool{"certs": false, "certs/ca": false, "certs/etcd-ca": true, "etcd": true, "etcd/local": true},
+		},
+		{
+			name:     "tags are case insensitive and can be negated",
+			options:  RunnerOptions{SkipTags: []string{"etcd"}, SkipPhases: []string{"!tag:network"}},
+			expected: map[string]bool{"certs": true, "certs/ca": true, "certs/etcd-ca": false, "etcd": true, "etcd/local": true},
+		},
+		{
+			name:     "tags are added to filter phases",
+			options:  RunnerOptions{FilterPhases: []string{"certs/ca"}, FilterTags: []string{"network"}},
+			expected: map[string]bool{"certs": false, "certs/ca": true, "certs/etcd-ca": false, "etcd": true, "etcd/local": true},
+		},
+		{
+			name:          "unknown tag",
+			options:       RunnerOptions{SkipTags: []string{"slow"}},
+			expectedError: `invalid phase tag "slow", known tags: [Network, etcd]`,
+		},
+		{
+			name:          "unknown phase name with suggestions",
+			options:       RunnerOptions{SkipPhases: []string{"certs/cx"}},
+			expectedError: `invalid phase name: certs/cx, did you mean "certs/ca"?`,
+		},
+		{
+			name:          "glob without matches",
+			options:       RunnerOptions{FilterPhases: []string{"kubeconfig/*"}},
+			expectedError: `invalid phase selector "kubeconfig/*": no phase matches it`,
+		},
+		{
+			name:          "invalid regular expression",
+			options:       RunnerOptions{FilterPhases: []string{"re:certs/(ca"}},
+			expectedError: `invalid phase selector "re:certs/(ca"`,
+		},
+	}
+	for _, u := range usecases {
+		t.Run(u.name, func(t *testing.T) {
+			var w = Runner{
+				Phases: []Phase{
+					phaseBuilder("certs",
+						phaseBuilder("ca"),
+						Phase{Name: "etcd-ca", Tags: []string{"etcd"}},
+					),
+					Phase{Name: "etcd", Tags: []string{"etcd", "Network"}, Phases: []Phase{
+						phaseBuilder("local"),
+					}},
+				},
+			}
+
+			w.prepareForExecution()
+			w.Options = u.options
+			actual, err := w.computePhaseRunFlags()
+			if u.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), u.expectedError) {
+					t.Errorf("expected error %q, got %v", u.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, u.expected) {
+				t.Errorf("\nactual:\n\t%v\nexpected:\n\t%v\n", actual, u.expected)
+			}
+		})
+	}
+}
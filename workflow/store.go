@@ -0,0 +1,147 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Store is a concurrency-safe key/value store scoped to an execution of the workflow, that
+// allows phases to publish results, e.g. generated tokens or paths, to be read by later phases.
+// Values must be serializable to JSON when the runner records a checkpoint; values restored
+// from a checkpoint are kept as json.RawMessage until they are read with StoreGet.
+type Store struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{values: map[string]interface{}{}}
+}
+
+// Put sets the value for the given key, replacing the existing one, if any.
+func (s *Store) Put(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Get returns the value for the given key, if any.
+func (s *Store) Get(key string) (interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Delete removes the value for the given key.
+func (s *Store) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// Keys returns the sorted list of the keys in the store.
+func (s *Store) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Snapshot returns a copy of the content of the store; values restored from a checkpoint
+// are decoded into generic JSON values.
+func (s *Store) Snapshot() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot := make(map[string]interface{}, len(s.values))
+	for k, v := range s.values {
+		if raw, ok := v.(json.RawMessage); ok {
+			var decoded interface{}
+			if err := json.Unmarshal(raw, &decoded); err == nil {
+				v = decoded
+			}
+		}
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+// marshal encodes the content of the store for the checkpoint file.
+func (s *Store) marshal() (map[string]json.RawMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.values) == 0 {
+		return nil, nil
+	}
+	encoded := make(map[string]json.RawMessage, len(s.values))
+	for k, v := range s.values {
+		if raw, ok := v.(json.RawMessage); ok {
+			encoded[k] = raw
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encode store value %q", k)
+		}
+		encoded[k] = b
+	}
+	return encoded, nil
+}
+
+// StorePut sets the value for the given key.
+func StorePut[T any](s *Store, key string, value T) {
+	s.Put(key, value)
+}
+
+// StoreGet returns the value for the given key as a value of type T.
+// It returns an error if the key is not in the store or if the value is not a T.
+func StoreGet[T any](s *Store, key string) (T, error) {
+	var zero T
+	v, ok := s.Get(key)
+	if !ok {
+		return zero, errors.Errorf("key %q not found in the store", key)
+	}
+	if t, ok := v.(T); ok {
+		return t, nil
+	}
+	// values restored from a checkpoint are decoded on first access
+	if raw, ok := v.(json.RawMessage); ok {
+		var t T
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return zero, errors.Wrapf(err, "failed to decode store value %q", key)
+		}
+		return t, nil
+	}
+	return zero, errors.Errorf("invalid type %T for store value %q, expected %s", v, key, reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// storeContextKey is the key of the Store in the context of the phases.
+type storeContextKey struct{}
+
+// WithStore returns a copy of the context carrying the given store.
+func WithStore(ctx context.Context, s *Store) context.Context {
+	return context.WithValue(ctx, storeContextKey{}, s)
+}
+
+// StoreFromContext returns the store of the execution of the workflow, carried by the context
+// of RunContext phases, or nil.
+func StoreFromContext(ctx context.Context) *Store {
+	s, _ := ctx.Value(storeContextKey{}).(*Store)
+	return s
+}
+
+// Store returns the store of the current, or of the last, execution of the workflow.
+// Phases implementing RunContext can access the store using StoreFromContext.
+func (e *Runner) Store() *Store {
+	return e.store
+}
@@ -0,0 +1,89 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type storeTestCert struct {
+	Path string `json:"path"`
+}
+
+func TestStoreGet(t *testing.T) {
+	s := NewStore()
+	StorePut(s, "token", "abcdef")
+
+	token, err := StoreGet[string](s, "token")
+	if err != nil || token != "abcdef" {
+		t.Errorf("expected token abcdef, got %q, %v", token, err)
+	}
+	if _, err := StoreGet[int](s, "token"); err == nil || !strings.Contains(err.Error(), "invalid type string") {
+		t.Errorf("expected invalid type error, got %v", err)
+	}
+	if _, err := StoreGet[string](s, "missing"); err == nil {
+		t.Error("expected error for missing key, got nil")
+	}
+}
+
+func TestRunStore(t *testing.T) {
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	fail := true
+	var got storeTestCert
+	var w = Runner{
+		Phases: []Phase{
+			{Name: "certs", RunContext: func(ctx context.Context, data RunData) error {
+				StorePut(StoreFromContext(ctx), "cert", storeTestCert{Path: "/etc/ca.crt"})
+				return nil
+			}},
+			{Name: "kubeconfig", RunContext: func(ctx context.Context, data RunData) error {
+				if fail {
+					return runFails(data)
+				}
+				cert, err := StoreGet[storeTestCert](StoreFromContext(ctx), "cert")
+				got = cert
+				return err
+			}},
+		},
+		Options: RunnerOptions{CheckpointFile: checkpointFile},
+	}
+
+	// the store is recorded in the checkpoint
+	if err := w.Run([]string{}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	checkpoint, err := LoadCheckpoint(checkpointFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var recorded storeTestCert
+	if err := json.Unmarshal(checkpoint.Store["cert"], &recorded); err != nil || recorded.Path != "/etc/ca.crt" {
+		t.Errorf("expected cert in the checkpoint, got %s", checkpoint.Store["cert"])
+	}
+
+	// the store is not included in the report, unless ReportStore is set
+	if w.Report().Store != nil {
+		t.Errorf("expected no store in the report, got %v", w.Report().Store)
+	}
+
+	// when resuming, the store is restored from the checkpoint
+	fail = false
+	w.Options.Resume = true
+	w.Options.ReportStore = true
+	if err := w.Run([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Path != "/etc/ca.crt" {
+		t.Errorf("expected cert restored from the checkpoint, got %v", got)
+	}
+
+	// the store is included in the report, if ReportStore is set
+	expected := map[string]interface{}{"cert": map[string]interface{}{"path": "/etc/ca.crt"}}
+	if !reflect.DeepEqual(w.Report().Store, expected) {
+		t.Errorf("\nstore:\n\t%v\nexpected:\n\t%v\n", w.Report().Store, expected)
+	}
+}
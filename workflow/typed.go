@@ -0,0 +1,214 @@
+package workflow
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// TypedPhase is a Phase whose functions receive the RunData as a value of type T,
+// thus avoiding type assertions in the phase implementation.
+// TypedPhase is converted into a Phase by the Phase method; when the RunData of the
+// workflow is not a T, the phase fails with an error instead of panicking.
+type TypedPhase[T any] struct {
+	// name of the phase.
+	// Phase name should be unique among peer phases (phases belonging to
+	// the same workflow or phases belonging to the same parent phase).
+	Name string
+
+	// Aliases returns the aliases for the phase.
+	Aliases []string
+
+	// Short description of the phase.
+	Short string
+
+	// Long returns the long description of the phase.
+	Long string
+
+	// Example returns the example for the phase.
+	Example string
+
+	// Hidden define if the phase should be hidden in the workflow help.
+	Hidden bool
+
+	// Phases defines a nested, ordered sequence of phases.
+	Phases []TypedPhase[T]
+
+	// RunAllSiblings allows to assign to a phase the responsibility to
+	// run all the sibling phases
+	// Nb. phase marked as RunAllSiblings can not have Run functions
+	RunAllSiblings bool
+
+	// Run defines a function implementing the phase action.
+	Run func(data T) error
+
+	// RunContext defines a context-aware function implementing the phase action.
+	// If both RunContext and Run are set, RunContext takes precedence.
+	RunContext func(ctx context.Context, data T) error
+
+	// Timeout defines the maximum duration of the phase action (if zero, no timeout).
+	Timeout time.Duration
+
+	// Retry defines how the phase action is retried when it fails (if nil, the phase is not retried).
+	Retry *RetryPolicy
+
+	// Rollback defines a function that undoes the phase action.
+	Rollback func(data T) error
+
+	// DryRun defines a function that describes the phase action without executing it.
+	DryRun func(data T) error
+
+	// RunIf define a function that implements a condition that should be checked
+	// before executing the phase action.
+	RunIf func(data T) (bool, error)
+
+	// RunIfReason defines a condition like RunIf, that additionally returns a human-readable
+	// reason explaining why the phase action is not executed.
+	RunIfReason func(data T) (ok bool, reason string, err error)
+
+	// InheritFlags defines the list of flags that the cobra command generated for this phase should Inherit
+	// from local flags defined in the parent command / or additional flags defined in the phase runner.
+	InheritFlags []string
+
+	// LocalFlags defines the list of flags that should be assigned to the cobra command generated
+	// for this phase.
+	LocalFlags *pflag.FlagSet
+
+	// ArgsValidator defines the positional arg function to be used for validating args for this phase
+	// If not set a phase will adopt the args of the top level command.
+	ArgsValidator cobra.PositionalArgs
+
+	// Tags are labels for selecting phases across different subtrees of the workflow.
+	Tags []string
+
+	// Dependencies is a list of phases that the specific phase depends on.
+	Dependencies []string
+}
+
+// AppendPhase adds the given phase to the nested, ordered sequence of phases.
+func (t *TypedPhase[T]) AppendPhase(phase TypedPhase[T]) {
+	t.Phases = append(t.Phases, phase)
+}
+
+// Phase converts the TypedPhase, and its nested phases, into a Phase.
+func (t TypedPhase[T]) Phase() Phase {
+	p := Phase{
+		Name:           t.Name,
+		Aliases:        t.Aliases,
+		Short:          t.Short,
+		Long:           t.Long,
+		Example:        t.Example,
+		Hidden:         t.Hidden,
+		RunAllSiblings: t.RunAllSiblings,
+		Timeout:        t.Timeout,
+		Retry:          t.Retry,
+		InheritFlags:   t.InheritFlags,
+		LocalFlags:     t.LocalFlags,
+		ArgsValidator:  t.ArgsValidator,
+		Tags:           t.Tags,
+		Dependencies:   t.Dependencies,
+	}
+	for _, child := range t.Phases {
+		p.Phases = append(p.Phases, child.Phase())
+	}
+
+	// the functions are set only if defined, because the Runner checks them against nil
+	if t.Run != nil {
+		p.Run = typedFunc(t.Run)
+	}
+	if t.RunContext != nil {
+		p.RunContext = func(ctx context.Context, data RunData) error {
+			d, err := AssertRunData[T](data)
+			if err != nil {
+				return err
+			}
+			return t.RunContext(ctx, d)
+		}
+	}
+	if t.Rollback != nil {
+		p.Rollback = typedFunc(t.Rollback)
+	}
+	if t.DryRun != nil {
+		p.DryRun = typedFunc(t.DryRun)
+	}
+	if t.RunIf != nil {
+		p.RunIf = func(data RunData) (bool, error) {
+			d, err := AssertRunData[T](data)
+			if err != nil {
+				return false, err
+			}
+			return t.RunIf(d)
+		}
+	}
+	if t.RunIfReason != nil {
+		p.RunIfReason = func(data RunData) (bool, string, error) {
+			d, err := AssertRunData[T](data)
+			if err != nil {
+				return false, "", err
+			}
+			return t.RunIfReason(d)
+		}
+	}
+	return p
+}
+
+// typedFunc adapts a function receiving a T to a function receiving the RunData.
+func typedFunc[T any](fn func(data T) error) func(data RunData) error {
+	return func(data RunData) error {
+		d, err := AssertRunData[T](data)
+		if err != nil {
+			return err
+		}
+		return fn(d)
+	}
+}
+
+// AssertRunData returns the RunData as a value of type T, or an error if the RunData is not a T.
+func AssertRunData[T any](data RunData) (T, error) {
+	d, ok := data.(T)
+	if !ok {
+		return d, errors.Errorf("invalid run data type %T, expected %s", data, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return d, nil
+}
+
+// TypedRunner is a Runner whose phases and data initializer are bound to RunData of type T,
+// so that a mismatch between the data created by the initializer and the data expected by
+// the phases is detected at compile time.
+// The embedded Runner provides the untyped API, e.g. for running the workflow or for
+// binding it to a cobra command.
+type TypedRunner[T any] struct {
+	*Runner
+}
+
+// NewTypedRunner return a new runner for composable workflows with RunData of type T.
+func NewTypedRunner[T any]() *TypedRunner[T] {
+	return &TypedRunner[T]{Runner: NewRunner()}
+}
+
+// AppendPhase adds the given phase to the ordered sequence of phases managed by the runner.
+func (e *TypedRunner[T]) AppendPhase(t TypedPhase[T]) {
+	e.Runner.AppendPhase(t.Phase())
+}
+
+// SetDataInitializer allows to setup a function that initialize the runtime data shared
+// among all the phases included in the workflow.
+func (e *TypedRunner[T]) SetDataInitializer(builder func(cmd *cobra.Command, args []string) (T, error)) {
+	e.Runner.SetDataInitializer(func(cmd *cobra.Command, args []string) (RunData, error) {
+		return builder(cmd, args)
+	})
+}
+
+// InitData triggers the creation of runtime data shared among all the phases included in the workflow.
+func (e *TypedRunner[T]) InitData(args []string) (T, error) {
+	data, err := e.Runner.InitData(args)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return AssertRunData[T](data)
+}
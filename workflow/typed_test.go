@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type typedTestData struct {
+	calls []string
+}
+
+func TestTypedRunner(t *testing.T) {
+	w := NewTypedRunner[*typedTestData]()
+	w.SetDataInitializer(func(cmd *cobra.Command, args []string) (*typedTestData, error) {
+		return &typedTestData{}, nil
+	})
+	w.AppendPhase(TypedPhase[*typedTestData]{
+		Name: "foo",
+		Run: func(data *typedTestData) error {
+			data.calls = append(data.calls, "foo")
+			return nil
+		},
+		Phases: []TypedPhase[*typedTestData]{
+			{
+				Name: "bar",
+				RunContext: func(ctx context.Context, data *typedTestData) error {
+					data.calls = append(data.calls, "bar")
+					return nil
+				},
+			},
+			{
+				Name:  "baz",
+				RunIf: func(data *typedTestData) (bool, error) { return false, nil },
+				Run: func(data *typedTestData) error {
+					data.calls = append(data.calls, "baz")
+					return nil
+				},
+			},
+		},
+	})
+
+	if err := w.Run([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := w.InitData([]string{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(data.calls, []string{"foo", "bar"}) {
+		t.Errorf("expected calls [foo bar], got %v", data.calls)
+	}
+}
+
+func TestTypedPhaseInvalidData(t *testing.T) {
+	var w = Runner{
+		Phases: []Phase{
+			TypedPhase[*typedTestData]{Name: "foo", Run: func(data *typedTestData) error { return nil }}.Phase(),
+		},
+	}
+	w.SetDataInitializer(func(cmd *cobra.Command, args []string) (RunData, error) {
+		return args, nil
+	})
+
+	err := w.Run([]string{})
+	if err == nil || !strings.Contains(err.Error(), "invalid run data type []string, expected *workflow.typedTestData") {
+		t.Errorf("expected invalid run data type error, got %v", err)
+	}
+}
+
+func TestTypedPhaseUndefinedFuncs(t *testing.T) {
+	p := TypedPhase[*typedTestData]{Name: "all", RunAllSiblings: true}.Phase()
+	if p.Run != nil || p.RunContext != nil || p.RunIf != nil || p.Rollback != nil || p.DryRun != nil {
+		t.Error("expected undefined functions to stay nil")
+	}
+}